@@ -0,0 +1,136 @@
+// evaluate 在标注语料上评估分类流水线
+//
+// 语料目录下每个子目录为一个分类，子目录中的文件为该分类的样本：
+//
+//	go run ./cmd/evaluate -dir testdata/corpus
+//	go run ./cmd/evaluate -dir testdata/corpus -compare candidate_pipeline.json -out result/eval
+//
+// -out 指定输出前缀时写出 <prefix>.json 以及 metrics、confusion、misclassified（对比时另有 comparison）CSV 表格
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"file-classifier/internal/bayes"
+	"file-classifier/internal/config"
+	"file-classifier/internal/evaluation"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+	"file-classifier/internal/utils"
+)
+
+func main() {
+	dir := flag.String("dir", "", "标注语料目录（每个子目录为一个分类）")
+	pipeline := flag.String("pipeline", utils.GetPipelineConfigPath(), "流水线配置文件")
+	compare := flag.String("compare", "", "对比的另一个流水线配置文件")
+	out := flag.String("out", "", "输出文件前缀，为空时只打印汇总")
+	flag.Parse()
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 只读加载分类体系、规则和本地模型，不写入版本历史
+	if err := taxonomy.Init(config.TaxonomyFile, ""); err != nil {
+		log.Fatalf("加载分类体系失败: %v", err)
+	}
+	if err := rules.Init(config.RulesFile); err != nil {
+		log.Fatalf("加载分类规则失败: %v", err)
+	}
+	if err := bayes.Load(config.BayesModelFile); err != nil {
+		log.Printf("加载本地分类模型失败: %v", err)
+	}
+
+	baseline, err := service.ReadPipelineConfig(*pipeline)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *compare == "" {
+		report, err := service.Evaluate(*dir, baseline, filepath.Base(*pipeline))
+		if err != nil {
+			log.Fatal(err)
+		}
+		printReport(report)
+		if *out != "" {
+			writeOutputs(*out, report, report)
+		}
+		return
+	}
+
+	candidate, err := service.ReadPipelineConfig(*compare)
+	if err != nil {
+		log.Fatal(err)
+	}
+	comparison, err := service.EvaluateCompare(*dir, baseline, candidate)
+	if err != nil {
+		log.Fatal(err)
+	}
+	comparison.Baseline.Name = filepath.Base(*pipeline)
+	comparison.Candidate.Name = filepath.Base(*compare)
+	printReport(comparison.Baseline)
+	printReport(comparison.Candidate)
+	fmt.Printf("准确率变化 %+.4f，Macro-F1 变化 %+.4f，修正 %d 个，退化 %d 个，结果不同 %d 个\n",
+		comparison.AccuracyDelta, comparison.MacroF1Delta, comparison.Fixed, comparison.Regressed, len(comparison.Disagreements))
+	if *out != "" {
+		writeOutputs(*out, comparison, comparison)
+	}
+}
+
+// printReport 打印评估汇总
+func printReport(r *evaluation.Report) {
+	fmt.Printf("== %s ==\n", r.Name)
+	fmt.Printf("样本 %d，正确 %d，准确率 %.4f，Macro-F1 %.4f\n", r.Total, r.Correct, r.Accuracy, r.MacroF1)
+	fmt.Printf("%-12s %8s %10s %8s %8s\n", "分类", "样本数", "精确率", "召回率", "F1")
+	for _, m := range r.Categories {
+		fmt.Printf("%-12s %8d %10.4f %8.4f %8.4f\n", m.Category, m.Support, m.Precision, m.Recall, m.F1)
+	}
+	for _, o := range r.Misclassified {
+		fmt.Printf("  分错: %s 标注 %s，预测 %s（%s）\n", o.Path, o.Expected, o.Predicted, o.Classifier)
+	}
+}
+
+// csvWriter Report 和 Comparison 都实现了该接口
+type csvWriter interface {
+	WriteCSV(w io.Writer, table string) error
+}
+
+// writeOutputs 写出 JSON 结果和各个 CSV 表格
+func writeOutputs(prefix string, result interface{}, tables csvWriter) {
+	if dir := filepath.Dir(prefix); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalf("创建输出目录失败: %v", err)
+		}
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("序列化评估结果失败: %v", err)
+	}
+	if err := os.WriteFile(prefix+".json", data, 0644); err != nil {
+		log.Fatalf("写入评估结果失败: %v", err)
+	}
+
+	names := []string{evaluation.TableMetrics, evaluation.TableConfusion, evaluation.TableMisclassified}
+	if _, ok := result.(*evaluation.Comparison); ok {
+		names = append(names, evaluation.TableComparison)
+	}
+	for _, table := range names {
+		f, err := os.Create(prefix + "_" + table + ".csv")
+		if err != nil {
+			log.Fatalf("创建 CSV 文件失败: %v", err)
+		}
+		err = tables.WriteCSV(f, table)
+		f.Close()
+		if err != nil {
+			log.Fatalf("写入 CSV 文件失败: %v", err)
+		}
+	}
+	fmt.Printf("评估结果已写入 %s.json 及 %s_*.csv\n", prefix, prefix)
+}
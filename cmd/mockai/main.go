@@ -0,0 +1,33 @@
+// mockai 本地模拟 AI 服务，以与 WPS AI 接口相同的协议响应分类请求，用于离线开发和测试
+//
+//	go run ./cmd/mockai -addr :8090 -script mock_ai.json -latency 200ms -failure-rate 0.1
+//
+// 然后以 AI_PROVIDER=wps AI_URL=http://localhost:8090/api/v2/aigc/completions 启动服务器，
+// 完整的 AI 调用链路（请求构造、HTTP、响应解析）都会经过该服务。
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"file-classifier/internal/ai"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "监听地址")
+	script := flag.String("script", "", "模拟脚本（JSON），为空时按候选分类名匹配内容")
+	latency := flag.Duration("latency", 0, "每次调用的延迟")
+	failureRate := flag.Float64("failure-rate", 0, "注入故障的请求比例（0~1）")
+	flag.Parse()
+
+	mock, err := ai.NewMock(ai.MockOptions{Script: *script, Latency: *latency, FailureRate: *failureRate})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{Addr: *addr, Handler: mock, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("模拟 AI 服务已启动: %s（任意路径均按 WPS 接口协议响应）", *addr)
+	log.Fatal(server.ListenAndServe())
+}
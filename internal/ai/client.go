@@ -0,0 +1,105 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sharedClient 所有服务实现共用的 HTTP 客户端，复用连接；超时按请求通过 context 控制
+var sharedClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		MaxIdleConns:          64,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	},
+}
+
+// StatusError 服务返回了非 200 状态码
+type StatusError struct {
+	Status     int
+	Body       string
+	RetryAfter time.Duration // 响应中的 Retry-After，未提供时为 0
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API请求失败，状态码: %d, 响应: %s", e.Status, e.Body)
+}
+
+// postJSON 发送 JSON 请求并解析 JSON 响应，timeout 为本次请求的超时
+func postJSON(url string, timeout time.Duration, headers map[string]string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求数据失败: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "file-classifier")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := sharedClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &StatusError{Status: resp.StatusCode, Body: string(respBody)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return statusErr
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("解析API响应失败: %v", err)
+	}
+	return nil
+}
+
+// retryable 判断错误是否值得重试：429、5xx 以及超时、连接失败等网络错误
+func retryable(err error) bool {
+	status := 0
+	var statusErr *StatusError
+	var mockErr *MockError
+	switch {
+	case errors.As(err, &statusErr):
+		status = statusErr.Status
+	case errors.As(err, &mockErr):
+		status = mockErr.Status
+	default:
+		var netErr net.Error
+		return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter 返回服务要求的重试等待时间
+func retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
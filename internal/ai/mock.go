@@ -0,0 +1,265 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 模拟 AI 服务：用于离线开发和测试。
+// 可以在进程内使用（provider 为 mock），也可以通过 cmd/mockai 作为本地 HTTP 服务，
+// 以与 WPS 接口相同的协议响应，使完整的 AI 调用链路可以被确定地测试。
+
+// MockOptions 模拟服务配置
+type MockOptions struct {
+	Script      string        `json:"script"`      // 脚本文件路径，为空时按候选分类名匹配内容
+	Latency     time.Duration `json:"latency"`     // 每次调用的延迟
+	FailureRate float64       `json:"failureRate"` // 0~1，按文件名和内容的哈希确定哪些请求失败
+}
+
+// MockRule 脚本中的一条规则：文件名和内容同时包含指定文本时按该规则响应
+type MockRule struct {
+	Title      string  `json:"title,omitempty"`   // 文件名包含的文本
+	Content    string  `json:"content,omitempty"` // 内容包含的文本
+	Category   string  `json:"category,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Reason     string  `json:"reason,omitempty"`
+	Reply      string  `json:"reply,omitempty"`   // 原样返回的回复文本，用于测试非 JSON 回复的解析
+	Error      string  `json:"error,omitempty"`   // 不为空时模拟调用失败
+	Status     int     `json:"status,omitempty"`  // 模拟失败时 HTTP 服务返回的状态码，默认 503
+	Latency    string  `json:"latency,omitempty"` // 覆盖全局延迟，如 "2s"
+	latency    time.Duration
+}
+
+// MockScript 模拟服务脚本，按顺序匹配规则，都不匹配时使用 Default
+type MockScript struct {
+	Rules   []MockRule `json:"rules"`
+	Default *MockRule  `json:"default,omitempty"`
+}
+
+// MockError 模拟的调用失败
+type MockError struct {
+	Status  int
+	Message string
+}
+
+func (e *MockError) Error() string {
+	return fmt.Sprintf("模拟 AI 调用失败（状态码 %d）: %s", e.Status, e.Message)
+}
+
+// Mock 模拟服务的响应逻辑，进程内服务和 HTTP 服务共用
+type Mock struct {
+	opts   MockOptions
+	script *MockScript
+	calls  atomic.Int64
+}
+
+// NewMock 创建模拟服务，配置了脚本时读取并校验脚本
+func NewMock(opts MockOptions) (*Mock, error) {
+	m := &Mock{opts: opts}
+	if opts.Script == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(opts.Script)
+	if err != nil {
+		return nil, fmt.Errorf("读取模拟脚本失败: %v", err)
+	}
+	var script MockScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("解析模拟脚本失败: %v", err)
+	}
+	rules := make([]*MockRule, 0, len(script.Rules)+1)
+	for i := range script.Rules {
+		rules = append(rules, &script.Rules[i])
+	}
+	if script.Default != nil {
+		rules = append(rules, script.Default)
+	}
+	for _, rule := range rules {
+		if rule.latency, err = parseTimeout(rule.Latency); err != nil {
+			return nil, err
+		}
+	}
+	m.script = &script
+	return m, nil
+}
+
+// Calls 返回已处理的请求数
+func (m *Mock) Calls() int64 {
+	return m.calls.Load()
+}
+
+// Respond 按脚本生成回复文本，等待配置的延迟后返回；模拟失败时返回 *MockError
+func (m *Mock) Respond(req Request) (string, error) {
+	m.calls.Add(1)
+	rule := m.match(req)
+
+	latency := m.opts.Latency
+	if rule != nil && rule.latency > 0 {
+		latency = rule.latency
+	}
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if rule != nil && rule.Error != "" {
+		status := rule.Status
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		return "", &MockError{Status: status, Message: rule.Error}
+	}
+	if m.opts.FailureRate > 0 && requestFraction(req) < m.opts.FailureRate {
+		return "", &MockError{Status: http.StatusServiceUnavailable, Message: "按失败率注入的故障"}
+	}
+
+	if rule == nil {
+		return mockReply(guessCategory(req), 0.8, "模拟服务：内容中出现了该分类名"), nil
+	}
+	if rule.Reply != "" {
+		return rule.Reply, nil
+	}
+	category, confidence, reason := rule.Category, rule.Confidence, rule.Reason
+	if category == "" {
+		category = guessCategory(req)
+	}
+	if confidence == 0 {
+		confidence = 0.9
+	}
+	if reason == "" {
+		reason = "模拟服务脚本"
+	}
+	return mockReply(category, confidence, reason), nil
+}
+
+// match 返回第一条匹配的规则，没有脚本或都不匹配且无默认规则时返回 nil
+func (m *Mock) match(req Request) *MockRule {
+	if m.script == nil {
+		return nil
+	}
+	for i := range m.script.Rules {
+		rule := &m.script.Rules[i]
+		if strings.Contains(req.Title, rule.Title) && strings.Contains(req.Content, rule.Content) {
+			return rule
+		}
+	}
+	return m.script.Default
+}
+
+// guessCategory 选择在文件名和内容中出现次数最多的候选分类，都未出现时返回 Other
+func guessCategory(req Request) string {
+	text := req.Title + "\n" + req.Content
+	best, bestCount := Other, 0
+	for _, c := range req.Candidates {
+		if n := strings.Count(text, c.Name); n > bestCount {
+			best, bestCount = c.Name, n
+		}
+	}
+	return best
+}
+
+// requestFraction 将请求映射到 [0,1) 区间，同一请求总是得到相同的值，与调用顺序和并发无关
+func requestFraction(req Request) float64 {
+	h := sha256.Sum256([]byte(req.Title + "\x00" + req.Content))
+	return float64(binary.BigEndian.Uint64(h[:8])>>11) / float64(1<<53)
+}
+
+func mockReply(category string, confidence float64, reason string) string {
+	data, _ := json.Marshal(map[string]interface{}{"category": category, "confidence": confidence, "reason": reason})
+	return string(data)
+}
+
+// mockUsage 按字符数粗略估算令牌用量
+func mockUsage(req Request, reply string) Usage {
+	prompt := len([]rune(req.Title)) + len([]rune(req.Content))
+	completion := len([]rune(reply))
+	return Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+// mockProvider 进程内的模拟服务
+type mockProvider struct {
+	mock *Mock
+}
+
+func (p *mockProvider) Name() string { return "mock" }
+
+func (p *mockProvider) Classify(req Request) (*Response, error) {
+	reply, err := p.mock.Respond(req)
+	if err != nil {
+		return nil, err
+	}
+	result := parseReply(reply, req.Candidates)
+	result.Model = "mock"
+	result.Usage = mockUsage(req, reply)
+	return &result, nil
+}
+
+// ServeHTTP 以 WPS 接口协议响应分类请求，供 cmd/mockai 使用
+func (m *Mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "只支持 POST 请求", http.StatusMethodNotAllowed)
+		return
+	}
+	var body WPSRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "解析请求失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := Request{
+		Title:      body.FunctionParameters.Title,
+		Content:    body.FunctionParameters.Content,
+		Candidates: parseTagList(body.FunctionParameters.CandidateTagList),
+	}
+
+	reply, err := m.Respond(req)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if mockErr, ok := err.(*MockError); ok {
+			status = mockErr.Status
+		}
+		log.Printf("模拟 AI: %s -> 失败 %d", req.Title, status)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	log.Printf("模拟 AI: %s -> %s", req.Title, reply)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WPSResponse{
+		Event:        "message",
+		FunctionCode: body.FunctionCode,
+		SessionID:    fmt.Sprintf("mock-%d", m.Calls()),
+		Reply:        reply,
+		Usage:        mockUsage(req, reply),
+		Platform:     "mock",
+		Model:        "mock",
+	})
+}
+
+// parseTagList 解析 WPS 请求中的候选标签列表，如 "'合同', '简历', '其它分类'"
+func parseTagList(list string) []Candidate {
+	var candidates []Candidate
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.Trim(strings.TrimSpace(tag), "'\"")
+		if tag != "" && tag != Other {
+			candidates = append(candidates, Candidate{Name: tag})
+		}
+	}
+	return candidates
+}
+
+func init() {
+	Register("mock", func(cfg Config) (Provider, error) {
+		mock, err := NewMock(cfg.Mock)
+		if err != nil {
+			return nil, err
+		}
+		return &mockProvider{mock: mock}, nil
+	})
+}
@@ -0,0 +1,76 @@
+package ai
+
+import "strings"
+
+// Ollama 本地服务的默认配置
+const (
+	OllamaDefaultURL   = "http://localhost:11434"
+	OllamaDefaultModel = "qwen2.5"
+)
+
+type ollamaRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Format   string        `json:"format"`
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+	} `json:"options"`
+}
+
+type ollamaResponse struct {
+	Model           string      `json:"model"`
+	Message         chatMessage `json:"message"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+// ollamaProvider Ollama 风格的本地 /api/chat 接口，要求模型输出 JSON
+type ollamaProvider struct {
+	cfg Config
+}
+
+func (p *ollamaProvider) Name() string { return "ollama" }
+
+func (p *ollamaProvider) Classify(req Request) (*Response, error) {
+	body := ollamaRequest{
+		Model: p.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt(req)},
+		},
+		Format: "json",
+	}
+	headers := map[string]string{}
+	if p.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.cfg.APIKey
+	}
+
+	var resp ollamaResponse
+	if err := postJSON(p.cfg.URL, p.cfg.Timeout, headers, body, &resp); err != nil {
+		return nil, err
+	}
+	result := parseReply(resp.Message.Content, req.Candidates)
+	result.Model = resp.Model
+	result.Usage = Usage{
+		PromptTokens:     resp.PromptEvalCount,
+		CompletionTokens: resp.EvalCount,
+		TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+	}
+	return &result, nil
+}
+
+func init() {
+	Register("ollama", func(cfg Config) (Provider, error) {
+		if cfg.URL == "" {
+			cfg.URL = OllamaDefaultURL
+		}
+		if !strings.HasSuffix(cfg.URL, "/api/chat") {
+			cfg.URL = strings.TrimRight(cfg.URL, "/") + "/api/chat"
+		}
+		if cfg.Model == "" {
+			cfg.Model = OllamaDefaultModel
+		}
+		return &ollamaProvider{cfg: cfg}, nil
+	})
+}
@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenAIDefaultURL OpenAI 兼容接口的默认地址
+const OpenAIDefaultURL = "https://api.openai.com/v1"
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage Usage `json:"usage"`
+}
+
+// openAIProvider OpenAI 兼容的 chat completions 接口（也适用于 DeepSeek、通义千问、vLLM 等兼容服务）
+type openAIProvider struct {
+	cfg Config
+}
+
+func (p *openAIProvider) Name() string { return "openai" }
+
+func (p *openAIProvider) Classify(req Request) (*Response, error) {
+	body := openAIRequest{
+		Model: p.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt(req)},
+		},
+	}
+	headers := map[string]string{}
+	if p.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.cfg.APIKey
+	}
+
+	var resp openAIResponse
+	if err := postJSON(p.cfg.URL, p.cfg.Timeout, headers, body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("API响应中没有结果")
+	}
+	result := parseReply(resp.Choices[0].Message.Content, req.Candidates)
+	result.Model = resp.Model
+	result.Usage = resp.Usage
+	return &result, nil
+}
+
+func init() {
+	Register("openai", func(cfg Config) (Provider, error) {
+		if cfg.URL == "" {
+			cfg.URL = OpenAIDefaultURL
+		}
+		// 只给出服务地址时补全接口路径
+		if !strings.HasSuffix(cfg.URL, "/chat/completions") {
+			cfg.URL = strings.TrimRight(cfg.URL, "/") + "/chat/completions"
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("openai 服务需要配置模型名称（AI_MODEL）")
+		}
+		return &openAIProvider{cfg: cfg}, nil
+	})
+}
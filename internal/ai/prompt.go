@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// promptVersion 提示词版本，修改提示词或回复解析时递增，使缓存的 AI 结果失效
+const promptVersion = 1
+
+// promptContentLimit 提示词中文件内容的最大字符数，避免超出模型上下文
+const promptContentLimit = 6000
+
+// replyTokenEstimate 估计用量时回复的令牌数（JSON 格式的分类结果和简短理由）
+const replyTokenEstimate = 200
+
+// systemPrompt 对话模型的系统提示词
+const systemPrompt = "你是一个文件分类助手。根据文件名和文件内容，从候选分类中选择最合适的一个；" +
+	"都不合适时选择「" + Other + "」。只输出 JSON，格式为 " +
+	`{"category": "分类名", "confidence": 0到1之间的小数, "reason": "简短的理由"}`
+
+// userPrompt 生成对话模型的用户提示词，包含候选分类及其说明
+func userPrompt(req Request) string {
+	var b strings.Builder
+	b.WriteString("候选分类：\n")
+	for _, c := range req.Candidates {
+		if c.Description != "" {
+			fmt.Fprintf(&b, "- %s：%s\n", c.Name, c.Description)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", c.Name)
+		}
+	}
+	fmt.Fprintf(&b, "- %s\n\n", Other)
+	fmt.Fprintf(&b, "文件名：%s\n文件内容：\n%s", req.Title, truncate(req.Content, promptContentLimit))
+	return b.String()
+}
+
+// EstimateTokens 粗略估计一次请求的提示词和回复令牌数，按每个字符一个令牌计，对中文内容偏保守；
+// 用于发出请求前预留预算
+func EstimateTokens(req Request) (prompt, completion int) {
+	return utf8.RuneCountInString(systemPrompt) + utf8.RuneCountInString(userPrompt(req)), replyTokenEstimate
+}
+
+// tagList 生成 WPS 接口使用的候选标签列表，如 "'合同', '简历', '其它分类'"
+func tagList(candidates []Candidate) string {
+	tags := make([]string, 0, len(candidates)+1)
+	for _, c := range candidates {
+		tags = append(tags, "'"+c.Name+"'")
+	}
+	tags = append(tags, "'"+Other+"'")
+	return strings.Join(tags, ", ")
+}
+
+// parseReply 解析模型回复：优先解析其中的 JSON 对象，失败时在回复中查找候选分类名
+func parseReply(reply string, candidates []Candidate) Response {
+	var result Response
+	if start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}"); start >= 0 && end > start {
+		if err := json.Unmarshal([]byte(reply[start:end+1]), &result); err == nil && result.Category != "" {
+			result.Category = strings.TrimSpace(result.Category)
+			return result
+		}
+	}
+
+	// 取回复中最早出现的候选分类
+	result = Response{Category: Other, Confidence: 0.5, Reason: "根据AI原始回复匹配: " + reply}
+	first := -1
+	for _, c := range candidates {
+		if i := strings.Index(reply, c.Name); i >= 0 && (first < 0 || i < first) {
+			first = i
+			result.Category = c.Name
+		}
+	}
+	return result
+}
+
+// truncate 按字符截断文本
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
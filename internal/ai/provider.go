@@ -0,0 +1,268 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AI 分类服务的统一抽象：不同厂商的接口通过 Provider 接入，
+// 由配置或环境变量选择具体实现，分类提示词中的候选分类来自当前分类体系。
+
+// Other AI 认为文件不属于任何候选分类时返回的分类名
+const Other = "其它分类"
+
+// DefaultTimeout 单次请求的默认超时
+const DefaultTimeout = 30 * time.Second
+
+// Candidate 提示词中的一个候选分类
+type Candidate struct {
+	Name        string
+	Description string
+}
+
+// Request 一次分类请求
+type Request struct {
+	Title      string // 文件名
+	Content    string // 提取出的文本
+	Candidates []Candidate
+}
+
+// Usage 令牌用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Response 分类结果；Category 为候选分类之一或 Other，也可能是 AI 自行给出的其他名称
+type Response struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+	Model      string  `json:"model,omitempty"`
+	Usage      Usage   `json:"usage"`
+}
+
+// Provider AI 分类服务
+// 网络或协议错误时返回 error，由调用方决定回退策略；实现应保证线程安全
+type Provider interface {
+	Name() string
+	Classify(req Request) (*Response, error)
+}
+
+// Config 服务配置
+type Config struct {
+	Provider string        `json:"provider"` // wps、openai、ollama、mock
+	URL      string        `json:"url"`      // 为空时使用各实现的默认地址
+	APIKey   string        `json:"-"`
+	Model    string        `json:"model"`
+	Timeout  time.Duration `json:"timeout"`
+	Limits   Limits        `json:"limits"`
+	Mock     MockOptions   `json:"mock"` // 仅 mock 服务使用
+}
+
+// ConfigFromEnv 从环境变量 AI_PROVIDER、AI_URL、AI_API_KEY、AI_MODEL、AI_TIMEOUT 读取配置，
+// 调用保护参数读取 AI_MAX_CONCURRENCY、AI_RATE_LIMIT、AI_MAX_RETRIES、AI_BREAKER_THRESHOLD、AI_BREAKER_COOLDOWN，
+// mock 服务另读取 AI_MOCK_SCRIPT、AI_MOCK_LATENCY、AI_MOCK_FAILURE_RATE；无效的值被忽略
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Provider: os.Getenv("AI_PROVIDER"),
+		URL:      os.Getenv("AI_URL"),
+		APIKey:   os.Getenv("AI_API_KEY"),
+		Model:    os.Getenv("AI_MODEL"),
+		Limits:   DefaultLimits(),
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "wps"
+	}
+	cfg.Timeout, _ = parseTimeout(os.Getenv("AI_TIMEOUT"))
+	for _, key := range []string{"concurrency", "rateLimit", "retries", "breakerThreshold", "breakerCooldown"} {
+		if value := os.Getenv(limitEnv[key]); value != "" {
+			cfg.Limits.set(key, value)
+		}
+	}
+	cfg.Mock.Script = os.Getenv("AI_MOCK_SCRIPT")
+	cfg.Mock.Latency, _ = parseTimeout(os.Getenv("AI_MOCK_LATENCY"))
+	cfg.Mock.FailureRate, _ = strconv.ParseFloat(os.Getenv("AI_MOCK_FAILURE_RATE"), 64)
+	return cfg
+}
+
+// WithOptions 用流水线配置中的选项（provider、url、apiKey、model、timeout，
+// concurrency、rateLimit、retries、breakerThreshold、breakerCooldown，mock 服务另有 script、latency、failureRate）覆盖配置
+func (c Config) WithOptions(options map[string]string) (Config, error) {
+	for key, value := range options {
+		switch key {
+		case "provider":
+			c.Provider = value
+		case "url":
+			c.URL = value
+		case "apiKey":
+			c.APIKey = value
+		case "model":
+			c.Model = value
+		case "timeout":
+			timeout, err := parseTimeout(value)
+			if err != nil {
+				return c, err
+			}
+			c.Timeout = timeout
+		case "script":
+			c.Mock.Script = value
+		case "latency":
+			latency, err := parseTimeout(value)
+			if err != nil {
+				return c, err
+			}
+			c.Mock.Latency = latency
+		case "failureRate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return c, fmt.Errorf("无效的失败率: %s", value)
+			}
+			c.Mock.FailureRate = rate
+		case "concurrency", "rateLimit", "retries", "breakerThreshold", "breakerCooldown":
+			if err := c.Limits.set(key, value); err != nil {
+				return c, err
+			}
+		default:
+			return c, fmt.Errorf("未知的 AI 配置项: %s", key)
+		}
+	}
+	return c, nil
+}
+
+// limitEnv 调用保护参数对应的环境变量
+var limitEnv = map[string]string{
+	"concurrency":      "AI_MAX_CONCURRENCY",
+	"rateLimit":        "AI_RATE_LIMIT",
+	"retries":          "AI_MAX_RETRIES",
+	"breakerThreshold": "AI_BREAKER_THRESHOLD",
+	"breakerCooldown":  "AI_BREAKER_COOLDOWN",
+}
+
+// set 按名称设置调用保护参数，值无效时保持原值并返回错误
+func (l *Limits) set(key, value string) error {
+	invalid := fmt.Errorf("无效的 %s: %s", key, value)
+	switch key {
+	case "concurrency", "retries", "breakerThreshold":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || (n == 0 && key != "retries") {
+			return invalid
+		}
+		switch key {
+		case "concurrency":
+			l.MaxConcurrency = n
+		case "retries":
+			l.MaxRetries = n
+		default:
+			l.BreakerThreshold = n
+		}
+	case "rateLimit":
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			return invalid
+		}
+		l.RateLimit = rate
+	case "breakerCooldown":
+		cooldown, err := parseTimeout(value)
+		if err != nil {
+			return invalid
+		}
+		l.BreakerCooldown = cooldown
+	}
+	return nil
+}
+
+// parseTimeout 解析超时，支持 "30s" 这样的时长或秒数，为空时返回 0
+func parseTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("无效的超时时间: %s", value)
+	}
+	return timeout, nil
+}
+
+// ProviderKey 返回服务的唯一标识（服务名、地址和模型），用于区分缓存的结果
+func ProviderKey(p Provider) string {
+	if g, ok := p.(*guardedProvider); ok {
+		return g.guard.key
+	}
+	return p.Name()
+}
+
+// ProviderModel 返回服务配置的模型名，未配置时为空
+func ProviderModel(p Provider) string {
+	if g, ok := p.(*guardedProvider); ok {
+		return g.guard.snapshot().Model
+	}
+	return ""
+}
+
+// Fingerprint 返回候选分类及提示词版本的指纹，候选分类或提示词变化时随之变化
+func Fingerprint(candidates []Candidate) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "prompt:%d\n", promptVersion)
+	for _, c := range candidates {
+		fmt.Fprintf(h, "%s\x00%s\n", c.Name, c.Description)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Factory 根据配置创建服务实例
+type Factory func(cfg Config) (Provider, error)
+
+var (
+	factories     = make(map[string]Factory)
+	factoriesLock sync.RWMutex
+)
+
+// Register 在 init() 中调用，按名称注册服务实现
+func Register(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	factories[name] = factory
+}
+
+// Names 返回已注册的服务名称
+func Names() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 按配置创建服务实例，返回的实例带有并发限制、限速、重试和熔断保护
+func New(cfg Config) (Provider, error) {
+	factoriesLock.RLock()
+	factory, ok := factories[cfg.Provider]
+	factoriesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的 AI 服务: %s（可选: %s）", cfg.Provider, strings.Join(Names(), ", "))
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	provider, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &guardedProvider{inner: provider, guard: guardFor(cfg)}, nil
+}
@@ -0,0 +1,325 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 每个 AI 服务（按服务名、地址和模型区分）共用一个保护层：
+// 并发上限、令牌桶限速、429/5xx 时指数退避重试，以及连续失败后熔断。
+// 熔断期间调用立即失败，流水线随即交给后续的本地分类器，不必等待超时。
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 正常
+	BreakerOpen     = "open"      // 熔断中，调用直接失败
+	BreakerHalfOpen = "half-open" // 冷却结束，放行一个探测请求
+)
+
+// 重试退避参数
+const (
+	backoffBase     = 500 * time.Millisecond
+	backoffMax      = 8 * time.Second
+	retryAfterLimit = 30 * time.Second
+)
+
+// ErrCircuitOpen 熔断期间的调用错误
+var ErrCircuitOpen = errors.New("AI 服务暂时不可用（已熔断）")
+
+// Limits 调用保护参数
+type Limits struct {
+	MaxConcurrency   int           `json:"maxConcurrency"`   // 同时进行的请求数上限
+	RateLimit        float64       `json:"rateLimit"`        // 每秒请求数，0 表示不限
+	MaxRetries       int           `json:"maxRetries"`       // 429/5xx/网络错误时的最大重试次数
+	BreakerThreshold int           `json:"breakerThreshold"` // 连续失败多少次后熔断
+	BreakerCooldown  time.Duration `json:"breakerCooldown"`  // 熔断后多久放行探测请求
+}
+
+// DefaultLimits 默认调用保护参数
+func DefaultLimits() Limits {
+	return Limits{
+		MaxConcurrency:   4,
+		RateLimit:        5,
+		MaxRetries:       3,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// ProviderStats 服务的调用统计和熔断状态
+type ProviderStats struct {
+	Provider            string    `json:"provider"`
+	URL                 string    `json:"url,omitempty"`
+	Model               string    `json:"model,omitempty"`
+	State               string    `json:"state"`
+	Calls               int64     `json:"calls"`     // 分类请求数
+	Attempts            int64     `json:"attempts"`  // 实际发出的请求数（含重试）
+	Successes           int64     `json:"successes"` // 成功的分类请求数
+	Failures            int64     `json:"failures"`  // 重试后仍失败的分类请求数
+	Retries             int64     `json:"retries"`
+	Rejected            int64     `json:"rejected"` // 熔断期间被拒绝的请求数
+	InFlight            int       `json:"inFlight"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	OpenedAt            time.Time `json:"openedAt,omitempty"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastErrorAt         time.Time `json:"lastErrorAt,omitempty"`
+	Limits              Limits    `json:"limits"`
+}
+
+// guard 单个服务的保护层和统计，由使用同一服务的所有流水线共享
+type guard struct {
+	key       string
+	mu        sync.Mutex
+	stats     ProviderStats
+	semaphore chan struct{}
+	tokens    float64
+	lastFill  time.Time
+	probing   bool // 半开状态下探测请求是否已放行
+}
+
+var (
+	guards     = make(map[string]*guard)
+	guardsLock sync.Mutex
+)
+
+// guardFor 返回服务对应的保护层，不存在时按 cfg 的保护参数创建。
+// 保护参数只在创建时设置一次（服务器启动加载流水线时），之后新建的流水线（评估、对比等）
+// 共用已有的保护层，不会改变其参数，也不会重新填满令牌桶
+func guardFor(cfg Config) *guard {
+	key := cfg.Provider + "|" + cfg.URL + "|" + cfg.Model
+	guardsLock.Lock()
+	defer guardsLock.Unlock()
+	if g, ok := guards[key]; ok {
+		return g
+	}
+	limits := cfg.Limits
+	if limits.MaxConcurrency <= 0 {
+		limits.MaxConcurrency = 1
+	}
+	g := &guard{
+		key:       key,
+		stats:     ProviderStats{Provider: cfg.Provider, URL: cfg.URL, Model: cfg.Model, State: BreakerClosed, Limits: limits},
+		semaphore: make(chan struct{}, limits.MaxConcurrency),
+		tokens:    burst(limits.RateLimit),
+		lastFill:  time.Now(),
+	}
+	guards[key] = g
+	return g
+}
+
+// burst 令牌桶容量：允许一秒内的请求集中发出
+func burst(rate float64) float64 {
+	if rate < 1 {
+		return 1
+	}
+	return rate
+}
+
+// allow 熔断检查；半开状态只放行一个探测请求
+func (g *guard) allow() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Calls++
+	switch g.stats.State {
+	case BreakerOpen:
+		if time.Since(g.stats.OpenedAt) < g.stats.Limits.BreakerCooldown {
+			g.stats.Rejected++
+			return ErrCircuitOpen
+		}
+		g.stats.State = BreakerHalfOpen
+		g.probing = true
+	case BreakerHalfOpen:
+		if g.probing {
+			g.stats.Rejected++
+			return ErrCircuitOpen
+		}
+		g.probing = true
+	}
+	return nil
+}
+
+// acquire 占用一个并发名额，返回释放函数
+func (g *guard) acquire() func() {
+	g.semaphore <- struct{}{}
+	g.mu.Lock()
+	g.stats.InFlight++
+	g.mu.Unlock()
+	return func() {
+		<-g.semaphore
+		g.mu.Lock()
+		g.stats.InFlight--
+		g.mu.Unlock()
+	}
+}
+
+// wait 按令牌桶限速，等待到有可用令牌
+func (g *guard) wait() {
+	for {
+		g.mu.Lock()
+		rate := g.stats.Limits.RateLimit
+		if rate <= 0 {
+			g.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		g.tokens += now.Sub(g.lastFill).Seconds() * rate
+		if max := burst(rate); g.tokens > max {
+			g.tokens = max
+		}
+		g.lastFill = now
+		if g.tokens >= 1 {
+			g.tokens--
+			g.mu.Unlock()
+			return
+		}
+		delay := time.Duration((1 - g.tokens) / rate * float64(time.Second))
+		g.mu.Unlock()
+		time.Sleep(delay)
+	}
+}
+
+// succeed 记录成功的调用并关闭熔断器
+func (g *guard) succeed() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Successes++
+	g.stats.ConsecutiveFailures = 0
+	g.stats.State = BreakerClosed
+	g.probing = false
+}
+
+// fail 记录重试后仍失败的调用，连续失败达到阈值或探测失败时熔断；
+// 400、401 等不可重试的错误说明服务可以访问，只计入失败次数，不计入连续失败，也不触发熔断
+func (g *guard) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Failures++
+	g.stats.LastError = err.Error()
+	g.stats.LastErrorAt = time.Now()
+	if !retryable(err) {
+		g.stats.ConsecutiveFailures = 0
+		g.stats.State = BreakerClosed
+		g.probing = false
+		return
+	}
+	g.stats.ConsecutiveFailures++
+	if g.stats.State == BreakerHalfOpen || g.stats.ConsecutiveFailures >= g.stats.Limits.BreakerThreshold {
+		if g.stats.State != BreakerOpen {
+			g.stats.OpenedAt = time.Now()
+		}
+		g.stats.State = BreakerOpen
+		g.probing = false
+	}
+}
+
+// open 熔断器是否已打开（其他请求的失败可能已触发熔断，此时不再重试）
+func (g *guard) open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats.State == BreakerOpen
+}
+
+func (g *guard) count(retry bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stats.Attempts++
+	if retry {
+		g.stats.Retries++
+	}
+}
+
+func (g *guard) snapshot() ProviderStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
+
+// guardedProvider 在服务实现外加上并发限制、限速、重试和熔断
+type guardedProvider struct {
+	inner Provider
+	guard *guard
+}
+
+func (p *guardedProvider) Name() string { return p.inner.Name() }
+
+func (p *guardedProvider) Classify(req Request) (*Response, error) {
+	// 排队等待并发名额期间熔断器可能已打开，因此在取得名额后再检查
+	release := p.guard.acquire()
+	defer release()
+	if err := p.guard.allow(); err != nil {
+		return nil, err
+	}
+
+	maxRetries := p.guard.snapshot().Limits.MaxRetries
+	for attempt := 0; ; attempt++ {
+		p.guard.wait()
+		p.guard.count(attempt > 0)
+		resp, err := p.inner.Classify(req)
+		if err == nil {
+			p.guard.succeed()
+			return resp, nil
+		}
+		if attempt >= maxRetries || !retryable(err) || p.guard.open() {
+			p.guard.fail(err)
+			if attempt > 0 {
+				return nil, fmt.Errorf("重试 %d 次后仍失败: %w", attempt, err)
+			}
+			return nil, err
+		}
+		time.Sleep(backoff(attempt, err))
+	}
+}
+
+// backoff 第 attempt 次失败后的等待时间：优先使用服务返回的 Retry-After，否则指数退避并加入随机抖动
+func backoff(attempt int, err error) time.Duration {
+	if wait := retryAfter(err); wait > 0 {
+		if wait > retryAfterLimit {
+			wait = retryAfterLimit
+		}
+		return wait
+	}
+	wait := backoffBase << attempt
+	if wait > backoffMax {
+		wait = backoffMax
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/4+1))
+}
+
+// Stats 返回所有服务的调用统计和熔断状态
+func Stats() []ProviderStats {
+	guardsLock.Lock()
+	list := make([]*guard, 0, len(guards))
+	for _, g := range guards {
+		list = append(list, g)
+	}
+	guardsLock.Unlock()
+
+	result := make([]ProviderStats, 0, len(list))
+	for _, g := range list {
+		result = append(result, g.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Provider != result[j].Provider {
+			return result[i].Provider < result[j].Provider
+		}
+		return result[i].URL+result[i].Model < result[j].URL+result[j].Model
+	})
+	return result
+}
+
+// ResetBreakers 手动关闭所有熔断器（服务恢复后无需等待冷却）
+func ResetBreakers() {
+	guardsLock.Lock()
+	defer guardsLock.Unlock()
+	for _, g := range guards {
+		g.mu.Lock()
+		g.stats.State = BreakerClosed
+		g.stats.ConsecutiveFailures = 0
+		g.probing = false
+		g.mu.Unlock()
+	}
+}
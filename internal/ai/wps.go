@@ -0,0 +1,90 @@
+package ai
+
+import "github.com/google/uuid"
+
+// WPS AI 接口的默认配置
+const (
+	WPSDefaultURL    = "http://kpp.wps.cn/api/v2/aigc/completions?=null"
+	WPSIntentionCode = "kdocs_public_autolabel_new"
+	WPSProductName   = "kdocs-public-pc"
+	WPSFunctionCode  = "doc_classify"
+	wpsUID           = "282987730"
+)
+
+// WPSRequest WPS AI 接口请求结构
+type WPSRequest struct {
+	UID                string            `json:"uid"`
+	Stream             bool              `json:"stream"`
+	FunctionCode       string            `json:"function_code"`
+	FunctionParameters WPSFunctionParams `json:"function_parameters"`
+	SecText            WPSSecurityText   `json:"sec_text"`
+}
+
+// WPSFunctionParams 功能参数
+type WPSFunctionParams struct {
+	Title            string `json:"title"`
+	Content          string `json:"content"`
+	CandidateTagList string `json:"candidate_tag_list"`
+}
+
+// WPSSecurityText 安全文本
+type WPSSecurityText struct {
+	From  string `json:"from"`
+	Scene string `json:"scene"`
+}
+
+// WPSResponse WPS AI 接口响应结构，Reply 为 JSON 格式的分类结果
+type WPSResponse struct {
+	Event        string `json:"event"`
+	FunctionCode string `json:"function_code"`
+	SessionID    string `json:"session_id"`
+	ReplyID      string `json:"reply_id"`
+	Reply        string `json:"reply"`
+	Usage        Usage  `json:"usage"`
+	Platform     string `json:"platform"`
+	Model        string `json:"model"`
+}
+
+// wpsProvider WPS 文档分类接口，候选分类通过 candidate_tag_list 传入
+type wpsProvider struct {
+	cfg Config
+}
+
+func (p *wpsProvider) Name() string { return "wps" }
+
+func (p *wpsProvider) Classify(req Request) (*Response, error) {
+	body := WPSRequest{
+		UID:          wpsUID,
+		FunctionCode: WPSFunctionCode,
+		FunctionParameters: WPSFunctionParams{
+			Title:            req.Title,
+			Content:          req.Content,
+			CandidateTagList: tagList(req.Candidates),
+		},
+		SecText: WPSSecurityText{From: "AI_WPS_VIP", Scene: "ai_autolabel"},
+	}
+	headers := map[string]string{
+		"Authorization":             "Bearer " + p.cfg.APIKey,
+		"Client-Request-Id":         uuid.NewString(),
+		"Ai-Gateway-Intention-Code": WPSIntentionCode,
+		"Ai-Gateway-Product-Name":   WPSProductName,
+	}
+
+	var resp WPSResponse
+	if err := postJSON(p.cfg.URL, p.cfg.Timeout, headers, body, &resp); err != nil {
+		return nil, err
+	}
+	result := parseReply(resp.Reply, req.Candidates)
+	result.Model = resp.Model
+	result.Usage = resp.Usage
+	return &result, nil
+}
+
+func init() {
+	Register("wps", func(cfg Config) (Provider, error) {
+		if cfg.URL == "" {
+			cfg.URL = WPSDefaultURL
+		}
+		return &wpsProvider{cfg: cfg}, nil
+	})
+}
@@ -0,0 +1,270 @@
+package bayes
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"file-classifier/internal/utils"
+)
+
+// 多项式朴素贝叶斯文本分类器。
+// 中文按字符一元/二元组切分，英文和数字按整词切分，无需分词词典，
+// 适合在用户手动确认或纠正过的文件上增量训练。
+
+// Sample 一条训练样本
+type Sample struct {
+	ID       string // 样本标识（通常为文件路径），用于确定性地划分训练/测试集
+	Text     string
+	Category string
+}
+
+// CategoryModel 单个分类的统计量
+type CategoryModel struct {
+	Docs   int            `json:"docs"`
+	Tokens int            `json:"tokens"`
+	Counts map[string]int `json:"counts"`
+}
+
+// Model 训练好的模型
+type Model struct {
+	Categories map[string]*CategoryModel `json:"categories"`
+	Vocabulary int                       `json:"vocabulary"`
+	Docs       int                       `json:"docs"`
+	TrainedAt  time.Time                 `json:"trainedAt"`
+
+	// 训练时在留出集上的评估结果
+	Accuracy    float64 `json:"accuracy"`
+	TestSamples int     `json:"testSamples"`
+}
+
+// Prediction 预测结果
+type Prediction struct {
+	Category    string
+	Probability float64  // 后验概率 0~1
+	Evidence    []string // 对结果贡献最大的若干特征
+}
+
+// Tokenize 将文本切分为特征：CJK 字符的一元组和二元组，其他字母数字按整词
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var prevCJK rune
+
+	flushWord := func() {
+		if len(word) > 1 {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			tokens = append(tokens, string(r))
+			if prevCJK != 0 {
+				tokens = append(tokens, string([]rune{prevCJK, r}))
+			}
+			prevCJK = r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			prevCJK = 0
+			word = append(word, r)
+		default:
+			prevCJK = 0
+			flushWord()
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// Train 在样本上训练模型
+func Train(samples []Sample) *Model {
+	m := &Model{Categories: make(map[string]*CategoryModel), TrainedAt: time.Now()}
+	vocab := make(map[string]struct{})
+
+	for _, s := range samples {
+		cm, ok := m.Categories[s.Category]
+		if !ok {
+			cm = &CategoryModel{Counts: make(map[string]int)}
+			m.Categories[s.Category] = cm
+		}
+		cm.Docs++
+		for _, t := range Tokenize(s.Text) {
+			cm.Counts[t]++
+			cm.Tokens++
+			vocab[t] = struct{}{}
+		}
+		m.Docs++
+	}
+	m.Vocabulary = len(vocab)
+	return m
+}
+
+// Predict 返回后验概率最高的分类
+func (m *Model) Predict(text string) Prediction {
+	if m == nil || len(m.Categories) == 0 {
+		return Prediction{Category: "未分类"}
+	}
+
+	// 训练集中从未出现的特征不提供任何信息，直接忽略，避免小样本分类被偏好
+	var tokens []string
+	for _, t := range Tokenize(text) {
+		if m.known(t) {
+			tokens = append(tokens, t)
+		}
+	}
+	if len(tokens) == 0 {
+		// 没有已知特征时只剩先验概率，结果只反映各分类的样本数
+		return Prediction{Category: "未分类"}
+	}
+	names := make([]string, 0, len(m.Categories))
+	for name := range m.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names) // 保证平局时结果稳定
+
+	vocab := float64(m.Vocabulary + 1)
+	scores := make([]float64, len(names))
+	for i, name := range names {
+		cm := m.Categories[name]
+		score := math.Log(float64(cm.Docs) / float64(m.Docs))
+		for _, t := range tokens {
+			score += math.Log((float64(cm.Counts[t]) + 1) / (float64(cm.Tokens) + vocab))
+		}
+		scores[i] = score
+	}
+
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	// softmax 归一化得到后验概率
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+
+	return Prediction{
+		Category:    names[best],
+		Probability: 1 / sum,
+		Evidence:    m.evidence(tokens, names[best], 3),
+	}
+}
+
+// known 判断特征是否在训练集中出现过
+func (m *Model) known(token string) bool {
+	for _, cm := range m.Categories {
+		if cm.Counts[token] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// evidence 返回对 category 最有区分度的 n 个特征
+func (m *Model) evidence(tokens []string, category string, n int) []string {
+	cm := m.Categories[category]
+	vocab := float64(m.Vocabulary + 1)
+
+	type scored struct {
+		token string
+		score float64
+	}
+	seen := make(map[string]bool)
+	var list []scored
+	for _, t := range tokens {
+		if seen[t] || cm.Counts[t] == 0 {
+			continue
+		}
+		seen[t] = true
+		in := math.Log((float64(cm.Counts[t]) + 1) / (float64(cm.Tokens) + vocab))
+		var others float64
+		for name, other := range m.Categories {
+			if name == category {
+				continue
+			}
+			others = math.Max(others, (float64(other.Counts[t])+1)/(float64(other.Tokens)+vocab))
+		}
+		if others == 0 {
+			others = 1 / vocab
+		}
+		list = append(list, scored{t, in - math.Log(others)})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		return list[i].token < list[j].token
+	})
+
+	var result []string
+	for i := 0; i < len(list) && i < n; i++ {
+		if list[i].score <= 0 {
+			break
+		}
+		result = append(result, list[i].token)
+	}
+	return result
+}
+
+// Evaluate 返回模型在样本上的准确率
+func (m *Model) Evaluate(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	correct := 0
+	for _, s := range samples {
+		if m.Predict(s.Text).Category == s.Category {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(samples))
+}
+
+var (
+	current     *Model
+	currentLock sync.RWMutex
+)
+
+// Current 返回当前生效的模型，未训练时返回 nil
+func Current() *Model {
+	currentLock.RLock()
+	defer currentLock.RUnlock()
+	return current
+}
+
+// SetCurrent 替换当前模型并持久化
+func SetCurrent(m *Model, path string) error {
+	if err := utils.WriteJSONFile(path, m); err != nil {
+		return fmt.Errorf("保存模型失败: %v", err)
+	}
+	currentLock.Lock()
+	current = m
+	currentLock.Unlock()
+	return nil
+}
+
+// Load 从文件加载模型；文件不存在时保持未训练状态
+func Load(path string) error {
+	var m Model
+	if err := utils.ReadJSONFile(path, &m); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	currentLock.Lock()
+	current = &m
+	currentLock.Unlock()
+	return nil
+}
@@ -0,0 +1,435 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"file-classifier/internal/ai"
+)
+
+// 分类缓存：按文件内容哈希保存提取出的文本和 AI 分类结果，
+// 重新扫描或重复上传内容相同的文件时直接复用，不再提取和请求 AI。
+// 存储格式与文件目录相同，为 JSON Lines 追加日志，启动时回放并压缩，过期的行过多时在运行中压缩。
+//
+// 文本的键包含提取器版本，AI 结果的键包含服务（服务名、地址、模型）和候选分类的指纹，
+// 升级提取器、更换模型或修改顶级分类后自动失效，不会用到过期的结果。
+
+// MaxEntries 最多保留的条目数，超出时丢弃最久未使用的条目
+const MaxEntries = 50000
+
+// evictTarget 运行中超出上限时淘汰到的条目数，留出余量，避免每次写入都排序淘汰
+const evictTarget = MaxEntries * 9 / 10
+
+// 运行中日志行数超过条目数的 compactRatio 倍、且多出 compactMin 行以上时重写日志
+const (
+	compactRatio = 2
+	compactMin   = 1000
+)
+
+// 条目类型
+const (
+	KindText = "text"
+	KindAI   = "ai"
+)
+
+// Entry 一条缓存
+type Entry struct {
+	Kind      string       `json:"kind"`
+	Key       string       `json:"key"`
+	Hash      string       `json:"hash"` // 文件内容哈希
+	Text      string       `json:"text,omitempty"`
+	AI        *ai.Response `json:"ai,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+
+	usedAt time.Time
+}
+
+// record 日志中的一行
+type record struct {
+	Op    string `json:"op"` // "put"、"delete" 或 "clear"
+	Entry *Entry `json:"entry,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Kind  string `json:"kind,omitempty"` // clear 时为空表示全部
+}
+
+// Stats 缓存统计
+type Stats struct {
+	Enabled     bool             `json:"enabled"`
+	Entries     map[string]int   `json:"entries"` // 按类型统计的条目数
+	Hits        map[string]int64 `json:"hits"`    // 启动以来的命中次数
+	Misses      map[string]int64 `json:"misses"`
+	HitRate     float64          `json:"hitRate"`
+	TextBytes   int64            `json:"textBytes"` // 缓存文本的总字节数
+	JournalSize int64            `json:"journalSize"`
+}
+
+var (
+	entries      = make(map[string]*Entry)
+	hits         = make(map[string]int64)
+	misses       = make(map[string]int64)
+	cacheLock    sync.Mutex
+	journal      *os.File
+	journalPath  string
+	journalLen   int64
+	journalLines int // 日志中的行数
+)
+
+// Init 打开（或创建）缓存文件，回放历史记录并压缩；未初始化时缓存不生效
+func Init(path string) error {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	loaded, err := replay(path)
+	if err != nil {
+		return err
+	}
+	evict(loaded, MaxEntries)
+	if err := compact(path, loaded); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("打开缓存文件失败: %v", err)
+	}
+	if info, err := f.Stat(); err == nil {
+		journalLen = info.Size()
+	}
+	if journal != nil {
+		journal.Close()
+	}
+	journal = f
+	journalPath = path
+	entries = loaded
+	journalLines = len(loaded)
+	return nil
+}
+
+// Close 关闭缓存文件
+func Close() error {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if journal == nil {
+		return nil
+	}
+	err := journal.Close()
+	journal = nil
+	return err
+}
+
+// Enabled 缓存是否已初始化
+func Enabled() bool {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	return journal != nil
+}
+
+// HashFile 计算文件内容的 sha256
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// TextKey 提取文本的缓存键，extractorVersion 为提取器的版本标识
+func TextKey(hash, extractorVersion string) string {
+	return "text|" + hash + "|" + extractorVersion
+}
+
+// AIKey AI 结果的缓存键：内容哈希、服务标识和候选分类指纹
+func AIKey(hash, provider, candidates string) string {
+	return "ai|" + hash + "|" + provider + "|" + candidates
+}
+
+// GetText 读取缓存的文本
+func GetText(key string) (string, bool) {
+	e := get(KindText, key)
+	if e == nil {
+		return "", false
+	}
+	return e.Text, true
+}
+
+// PutText 缓存提取出的文本
+func PutText(key, hash, text string) {
+	put(&Entry{Kind: KindText, Key: key, Hash: hash, Text: text})
+}
+
+// GetAI 读取缓存的 AI 结果
+func GetAI(key string) (*ai.Response, bool) {
+	e := get(KindAI, key)
+	if e == nil || e.AI == nil {
+		return nil, false
+	}
+	resp := *e.AI
+	return &resp, true
+}
+
+// PutAI 缓存 AI 结果
+func PutAI(key, hash string, resp *ai.Response) {
+	stored := *resp
+	put(&Entry{Kind: KindAI, Key: key, Hash: hash, AI: &stored})
+}
+
+func get(kind, key string) *Entry {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if journal == nil {
+		return nil
+	}
+	e, ok := entries[key]
+	if !ok {
+		misses[kind]++
+		return nil
+	}
+	hits[kind]++
+	e.usedAt = time.Now()
+	return e
+}
+
+func put(e *Entry) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if journal == nil {
+		return
+	}
+	defer maybeCompact()
+	e.CreatedAt = time.Now()
+	e.usedAt = e.CreatedAt
+	if err := appendRecord(record{Op: "put", Entry: e}); err != nil {
+		return
+	}
+	entries[e.Key] = e
+	if len(entries) > MaxEntries {
+		for _, key := range evict(entries, evictTarget) {
+			// 记录删除，重启回放时不再恢复被淘汰的条目
+			if err := appendRecord(record{Op: "delete", Key: key}); err != nil {
+				break
+			}
+		}
+	}
+}
+
+// Invalidate 删除缓存：kind 为空时不区分类型；hashes 为空时删除该类型的全部条目
+// 返回删除的条目数
+func Invalidate(kind string, hashes []string) (int, error) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if journal == nil {
+		return 0, fmt.Errorf("缓存未启用")
+	}
+	defer maybeCompact()
+
+	if len(hashes) == 0 {
+		if err := appendRecord(record{Op: "clear", Kind: kind}); err != nil {
+			return 0, err
+		}
+		return clearKind(entries, kind), nil
+	}
+
+	wanted := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		wanted[h] = true
+	}
+	removed := 0
+	for key, e := range entries {
+		if !wanted[e.Hash] || (kind != "" && e.Kind != kind) {
+			continue
+		}
+		if err := appendRecord(record{Op: "delete", Key: key}); err != nil {
+			return removed, err
+		}
+		delete(entries, key)
+		removed++
+	}
+	return removed, nil
+}
+
+// GetStats 返回缓存统计
+func GetStats() Stats {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	stats := Stats{
+		Enabled:     journal != nil,
+		Entries:     map[string]int{KindText: 0, KindAI: 0},
+		Hits:        map[string]int64{KindText: hits[KindText], KindAI: hits[KindAI]},
+		Misses:      map[string]int64{KindText: misses[KindText], KindAI: misses[KindAI]},
+		JournalSize: journalLen,
+	}
+	for _, e := range entries {
+		stats.Entries[e.Kind]++
+		stats.TextBytes += int64(len(e.Text))
+	}
+	var h, m int64
+	for _, kind := range []string{KindText, KindAI} {
+		h += hits[kind]
+		m += misses[kind]
+	}
+	if h+m > 0 {
+		stats.HitRate = float64(h) / float64(h+m)
+	}
+	return stats
+}
+
+// clearKind 删除指定类型（为空时全部）的条目，返回删除数
+func clearKind(m map[string]*Entry, kind string) int {
+	removed := 0
+	for key, e := range m {
+		if kind == "" || e.Kind == kind {
+			delete(m, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// appendRecord 追加一行日志，调用方需持有锁
+func appendRecord(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("序列化缓存记录失败: %v", err)
+	}
+	data = append(data, '\n')
+	n, err := journal.Write(data)
+	journalLen += int64(n)
+	journalLines++
+	if err != nil {
+		return fmt.Errorf("写入缓存文件失败: %v", err)
+	}
+	return nil
+}
+
+// maybeCompact 日志中过期的行过多时重写日志，调用方需持有锁
+func maybeCompact() {
+	if journal == nil || journalLines-len(entries) < compactMin || journalLines <= compactRatio*len(entries) {
+		return
+	}
+	journal.Close()
+	if err := compact(journalPath, entries); err != nil {
+		log.Printf("压缩缓存文件失败: %v", err)
+	} else {
+		journalLines = len(entries)
+	}
+	f, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		log.Printf("重新打开缓存文件失败: %v", err)
+		journal = nil
+		return
+	}
+	if info, err := f.Stat(); err == nil {
+		journalLen = info.Size()
+	}
+	journal = f
+}
+
+// replay 读取日志并重建缓存表
+func replay(path string) (map[string]*Entry, error) {
+	loaded := make(map[string]*Entry)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return loaded, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开缓存文件失败: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// 进程异常退出可能留下半行，跳过即可
+			continue
+		}
+		switch r.Op {
+		case "put":
+			if r.Entry != nil {
+				r.Entry.usedAt = r.Entry.CreatedAt
+				loaded[r.Entry.Key] = r.Entry
+			}
+		case "delete":
+			delete(loaded, r.Key)
+		case "clear":
+			clearKind(loaded, r.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取缓存文件失败: %v", err)
+	}
+	return loaded, nil
+}
+
+// evict 条目数超过 limit 时丢弃最久未使用的条目，返回被丢弃的键
+func evict(m map[string]*Entry, limit int) []string {
+	if len(m) <= limit {
+		return nil
+	}
+	list := make([]*Entry, 0, len(m))
+	for _, e := range m {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].usedAt.After(list[j].usedAt) })
+	removed := make([]string, 0, len(list)-limit)
+	for _, e := range list[limit:] {
+		delete(m, e.Key)
+		removed = append(removed, e.Key)
+	}
+	return removed
+}
+
+// compact 将当前条目重写为一份紧凑的日志（先写临时文件再原子替换）
+func compact(path string, m map[string]*Entry) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(f)
+	for _, key := range keys {
+		data, err := json.Marshal(record{Op: "put", Entry: m[key]})
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("序列化缓存记录失败: %v", err)
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("替换缓存文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,461 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"file-classifier/internal/models"
+)
+
+// 文件目录（catalog）持久化所有分类结果，替代原先的内存统计表。
+// 存储格式为 JSON Lines 追加日志：每次变更追加一行，启动时回放并压缩，过期的行过多时在运行中压缩，
+// 无需外部数据库，进程重启后分类结果仍然保留。
+
+// entry 日志中的一条记录
+type entry struct {
+	Op       string           `json:"op"` // "put"、"delete" 或 "override"
+	Path     string           `json:"path"`
+	File     *models.FileInfo `json:"file,omitempty"`
+	Override *models.Override `json:"override,omitempty"`
+}
+
+// 运行中日志行数超过有效行数（每个文件的记录及其手动分类历史）的 compactRatio 倍、
+// 且多出 compactMin 行以上时重写日志，反复扫描的长期运行的服务不会无限增长
+const (
+	compactRatio = 2
+	compactMin   = 1000
+)
+
+var (
+	records      = make(map[string]models.FileInfo)
+	history      = make(map[string][]models.Override) // 每个文件的手动分类历史
+	recordsLock  sync.RWMutex
+	journal      *os.File
+	journalPath  string
+	journalLines int // 日志中的行数
+)
+
+// ErrNotInitialized 未调用 Init 时返回
+var ErrNotInitialized = errors.New("文件目录未初始化")
+
+// Init 打开（或创建）目录文件，回放历史记录并压缩日志
+func Init(path string) error {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	loaded, overrides, err := replay(path)
+	if err != nil {
+		return err
+	}
+
+	if err := compact(path, loaded, overrides); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("打开文件目录失败: %v", err)
+	}
+	if journal != nil {
+		journal.Close()
+	}
+	journal = f
+	journalPath = path
+	records = loaded
+	history = overrides
+	journalLines = liveLines()
+	return nil
+}
+
+// Close 关闭日志文件
+func Close() error {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	if journal == nil {
+		return nil
+	}
+	err := journal.Close()
+	journal = nil
+	return err
+}
+
+// Put 新增或更新文件记录，保留首次入库时间
+func Put(info models.FileInfo) (models.FileInfo, error) {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+
+	now := time.Now()
+	if old, ok := records[info.Path]; ok && !old.CreatedAt.IsZero() {
+		info.CreatedAt = old.CreatedAt
+	} else if info.CreatedAt.IsZero() {
+		info.CreatedAt = now
+	}
+	info.UpdatedAt = now
+
+	if err := appendEntry(entry{Op: "put", Path: info.Path, File: &info}); err != nil {
+		return info, err
+	}
+	records[info.Path] = info
+	return info, nil
+}
+
+// Get 按相对路径获取文件记录
+func Get(path string) (models.FileInfo, bool) {
+	recordsLock.RLock()
+	defer recordsLock.RUnlock()
+	info, ok := records[path]
+	return info, ok
+}
+
+// Reassign 将文件手动归入指定分类，并记录操作人、时间和原分类
+// 手动分类会保存在记录中，后续重新扫描时保留
+func Reassign(path, category, user string) (models.FileInfo, error) {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+
+	info, ok := records[path]
+	if !ok {
+		return info, fmt.Errorf("文件不存在: %s", path)
+	}
+
+	now := time.Now()
+	override := models.Override{
+		Category:         category,
+		PreviousCategory: info.Category,
+		User:             user,
+		Time:             now,
+	}
+	if err := appendEntry(entry{Op: "override", Path: path, Override: &override}); err != nil {
+		return info, err
+	}
+	history[path] = append(history[path], override)
+
+	info.Category = category
+	info.Type = "manual"
+	info.Confidence = 1
+	info.Matches = nil
+	info.Reason = fmt.Sprintf("由 %s 手动归类", user)
+	info.Override = &override
+	info.UpdatedAt = now
+	if err := appendEntry(entry{Op: "put", Path: path, File: &info}); err != nil {
+		return info, err
+	}
+	records[path] = info
+	return info, nil
+}
+
+// Recategorize 将分类 from 下的所有文件移到分类 to（含手动指定的分类），返回移动的文件数
+// reason 非空时替换文件的分类说明
+func Recategorize(from, to, reason string) (int, error) {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+
+	moved := 0
+	now := time.Now()
+	for path, info := range records {
+		if info.Category != from {
+			continue
+		}
+		info.Category = to
+		if info.Override != nil && info.Override.Category == from {
+			override := *info.Override
+			override.Category = to
+			info.Override = &override
+		}
+		if reason != "" {
+			info.Reason = reason
+		}
+		info.UpdatedAt = now
+		if err := appendEntry(entry{Op: "put", Path: path, File: &info}); err != nil {
+			return moved, err
+		}
+		records[path] = info
+		moved++
+	}
+	return moved, nil
+}
+
+// UpdateTags 为文件添加/移除用户标签
+func UpdateTags(path string, add, remove []string) (models.FileInfo, error) {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+
+	info, ok := records[path]
+	if !ok {
+		return info, fmt.Errorf("文件不存在: %s", path)
+	}
+
+	removed := make(map[string]bool)
+	for _, tag := range models.NormalizeTags(remove) {
+		removed[tag] = true
+	}
+	var tags []string
+	for _, tag := range append(info.Tags, add...) {
+		if !removed[strings.TrimSpace(tag)] {
+			tags = append(tags, tag)
+		}
+	}
+	info.Tags = models.NormalizeTags(tags)
+	info.UpdatedAt = time.Now()
+
+	if err := appendEntry(entry{Op: "put", Path: path, File: &info}); err != nil {
+		return info, err
+	}
+	records[path] = info
+	return info, nil
+}
+
+// TagCounts 统计每个标签（含规则标签）对应的文件数
+func TagCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, info := range List() {
+		for _, tag := range info.AllTags() {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// History 返回文件的手动分类历史，按时间先后排列
+func History(path string) []models.Override {
+	recordsLock.RLock()
+	defer recordsLock.RUnlock()
+	result := make([]models.Override, len(history[path]))
+	copy(result, history[path])
+	return result
+}
+
+// Delete 删除文件记录
+func Delete(path string) error {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+	if _, ok := records[path]; !ok {
+		return nil
+	}
+	if err := appendEntry(entry{Op: "delete", Path: path}); err != nil {
+		return err
+	}
+	delete(records, path)
+	delete(history, path)
+	return nil
+}
+
+// Prune 删除不在 keep 中的记录（例如磁盘上已不存在的文件），返回删除数量
+func Prune(keep map[string]bool) (int, error) {
+	recordsLock.Lock()
+	defer recordsLock.Unlock()
+	defer maybeCompact()
+
+	removed := 0
+	for path := range records {
+		if keep[path] {
+			continue
+		}
+		if err := appendEntry(entry{Op: "delete", Path: path}); err != nil {
+			return removed, err
+		}
+		delete(records, path)
+		delete(history, path)
+		removed++
+	}
+	return removed, nil
+}
+
+// List 返回所有文件记录，按路径排序
+func List() []models.FileInfo {
+	recordsLock.RLock()
+	defer recordsLock.RUnlock()
+
+	result := make([]models.FileInfo, 0, len(records))
+	for _, info := range records {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// Stats 按分类汇总文件记录；categories 中的分类即使为空也会出现在结果中
+func Stats(categories []string) map[string]models.CategoryStats {
+	result := make(map[string]models.CategoryStats)
+	for _, category := range categories {
+		result[category] = models.CategoryStats{Count: 0, Files: []models.FileInfo{}}
+	}
+
+	for _, info := range List() {
+		stats, ok := result[info.Category]
+		if !ok {
+			stats = models.CategoryStats{Files: []models.FileInfo{}}
+		}
+		stats.Files = append(stats.Files, info)
+		stats.Count = len(stats.Files)
+		for _, tag := range info.AllTags() {
+			if stats.Tags == nil {
+				stats.Tags = make(map[string]int)
+			}
+			stats.Tags[tag]++
+		}
+		result[info.Category] = stats
+	}
+	return result
+}
+
+// appendEntry 追加一条日志，调用方需持有写锁
+func appendEntry(e entry) error {
+	if journal == nil {
+		return ErrNotInitialized
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("序列化文件记录失败: %v", err)
+	}
+	data = append(data, '\n')
+	if _, err := journal.Write(data); err != nil {
+		return fmt.Errorf("写入文件目录失败: %v", err)
+	}
+	journalLines++
+	return nil
+}
+
+// liveLines 压缩后日志的行数，调用方需持有锁
+func liveLines() int {
+	n := len(records)
+	for _, h := range history {
+		n += len(h)
+	}
+	return n
+}
+
+// maybeCompact 日志中过期的行过多时重写日志，调用方需持有写锁
+func maybeCompact() {
+	if journal == nil || journalLines < compactMin || journalLines <= compactRatio*len(records) {
+		return
+	}
+	live := liveLines()
+	if journalLines-live < compactMin || journalLines <= compactRatio*live {
+		return
+	}
+	journal.Close()
+	if err := compact(journalPath, records, history); err != nil {
+		log.Printf("压缩文件目录失败: %v", err)
+	} else {
+		journalLines = live
+	}
+	f, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		log.Printf("重新打开文件目录失败: %v", err)
+		journal = nil
+		return
+	}
+	journal = f
+}
+
+// replay 读取日志并重建记录表及手动分类历史
+func replay(path string) (map[string]models.FileInfo, map[string][]models.Override, error) {
+	loaded := make(map[string]models.FileInfo)
+	overrides := make(map[string][]models.Override)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return loaded, overrides, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开文件目录失败: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// 进程异常退出可能留下半行，跳过即可
+			continue
+		}
+		switch e.Op {
+		case "put":
+			if e.File != nil {
+				loaded[e.Path] = *e.File
+			}
+		case "delete":
+			delete(loaded, e.Path)
+			delete(overrides, e.Path)
+		case "override":
+			if e.Override != nil {
+				overrides[e.Path] = append(overrides[e.Path], *e.Override)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("读取文件目录第 %d 行失败: %v", line, err)
+	}
+	return loaded, overrides, nil
+}
+
+// compact 将当前记录重写为一份紧凑的日志（先写临时文件再原子替换）
+func compact(path string, loaded map[string]models.FileInfo, overrides map[string][]models.Override) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+
+	w := bufio.NewWriter(f)
+	paths := make([]string, 0, len(loaded))
+	for p := range loaded {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		info := loaded[p]
+		lines := []entry{}
+		for i := range overrides[p] {
+			lines = append(lines, entry{Op: "override", Path: p, Override: &overrides[p][i]})
+		}
+		lines = append(lines, entry{Op: "put", Path: p, File: &info})
+		for _, e := range lines {
+			data, err := json.Marshal(e)
+			if err != nil {
+				f.Close()
+				return fmt.Errorf("序列化文件记录失败: %v", err)
+			}
+			w.Write(data)
+			w.WriteByte('\n')
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("替换文件目录失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 分类效果评估：在按分类子目录组织的标注语料上统计每个分类的
+// 精确率、召回率、F1 以及混淆矩阵，并列出分错的文件。
+
+// Sample 一个标注样本，Expected 为所在子目录名
+type Sample struct {
+	Path     string // 磁盘路径
+	RelPath  string // 相对语料目录的路径
+	Expected string
+}
+
+// Outcome 一个样本的分类结果
+type Outcome struct {
+	Path       string  `json:"path"`
+	Expected   string  `json:"expected"`
+	Predicted  string  `json:"predicted"`
+	Classifier string  `json:"classifier"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// CategoryMetrics 单个分类的指标
+type CategoryMetrics struct {
+	Category      string  `json:"category"`
+	Support       int     `json:"support"`       // 标注为该分类的样本数
+	Predicted     int     `json:"predicted"`     // 预测为该分类的样本数
+	TruePositives int     `json:"truePositives"` // 预测正确的样本数
+	Precision     float64 `json:"precision"`
+	Recall        float64 `json:"recall"`
+	F1            float64 `json:"f1"`
+}
+
+// Report 一次评估的结果
+type Report struct {
+	Name          string            `json:"name"`
+	Total         int               `json:"total"`
+	Correct       int               `json:"correct"`
+	Accuracy      float64           `json:"accuracy"`
+	MacroF1       float64           `json:"macroF1"` // 有样本的分类的 F1 平均值
+	Categories    []CategoryMetrics `json:"categories"`
+	Labels        []string          `json:"labels"`    // 混淆矩阵的行列顺序
+	Confusion     [][]int           `json:"confusion"` // 行为标注分类，列为预测分类
+	Misclassified []Outcome         `json:"misclassified"`
+
+	outcomes []Outcome
+}
+
+// LoadCorpus 读取语料目录：每个一级子目录名为分类，其下（含更深层目录）的文件为该分类的样本
+// 隐藏文件和直接放在语料根目录下的文件会被忽略
+func LoadCorpus(dir string) ([]Sample, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取语料目录失败: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("语料路径不是目录: %s", dir)
+	}
+
+	var samples []Sample
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") && path != dir {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) < 2 {
+			return nil
+		}
+		samples = append(samples, Sample{Path: path, RelPath: filepath.ToSlash(rel), Expected: parts[0]})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历语料目录失败: %v", err)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("语料目录中没有样本，请按分类建立子目录")
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].RelPath < samples[j].RelPath })
+	return samples, nil
+}
+
+// Compute 根据分类结果计算指标
+func Compute(name string, outcomes []Outcome) *Report {
+	r := &Report{Name: name, Total: len(outcomes), Misclassified: []Outcome{}, outcomes: outcomes}
+
+	labelSet := make(map[string]bool)
+	for _, o := range outcomes {
+		labelSet[o.Expected] = true
+		labelSet[o.Predicted] = true
+	}
+	for label := range labelSet {
+		r.Labels = append(r.Labels, label)
+	}
+	sort.Strings(r.Labels)
+	index := make(map[string]int, len(r.Labels))
+	for i, label := range r.Labels {
+		index[label] = i
+	}
+
+	r.Confusion = make([][]int, len(r.Labels))
+	for i := range r.Confusion {
+		r.Confusion[i] = make([]int, len(r.Labels))
+	}
+	for _, o := range outcomes {
+		r.Confusion[index[o.Expected]][index[o.Predicted]]++
+		if o.Expected == o.Predicted {
+			r.Correct++
+		} else {
+			r.Misclassified = append(r.Misclassified, o)
+		}
+	}
+	if r.Total > 0 {
+		r.Accuracy = float64(r.Correct) / float64(r.Total)
+	}
+
+	var f1Sum float64
+	var withSupport int
+	for i, label := range r.Labels {
+		m := CategoryMetrics{Category: label, TruePositives: r.Confusion[i][i]}
+		for j := range r.Labels {
+			m.Support += r.Confusion[i][j]
+			m.Predicted += r.Confusion[j][i]
+		}
+		if m.Predicted > 0 {
+			m.Precision = float64(m.TruePositives) / float64(m.Predicted)
+		}
+		if m.Support > 0 {
+			m.Recall = float64(m.TruePositives) / float64(m.Support)
+			withSupport++
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		if m.Support > 0 {
+			f1Sum += m.F1
+		}
+		r.Categories = append(r.Categories, m)
+	}
+	if withSupport > 0 {
+		r.MacroF1 = f1Sum / float64(withSupport)
+	}
+	return r
+}
+
+// metrics 返回分类的指标，不存在时返回零值
+func (r *Report) metrics(category string) CategoryMetrics {
+	for _, m := range r.Categories {
+		if m.Category == category {
+			return m
+		}
+	}
+	return CategoryMetrics{Category: category}
+}
+
+// CSV 表格名称
+const (
+	TableMetrics       = "metrics"
+	TableConfusion     = "confusion"
+	TableMisclassified = "misclassified"
+	TableComparison    = "comparison"
+)
+
+// WriteCSV 以 CSV 格式写出 metrics、confusion 或 misclassified 表
+func (r *Report) WriteCSV(w io.Writer, table string) error {
+	cw := csv.NewWriter(w)
+	switch table {
+	case TableMetrics, "":
+		cw.Write([]string{"category", "support", "predicted", "true_positives", "precision", "recall", "f1"})
+		for _, m := range r.Categories {
+			cw.Write([]string{m.Category, strconv.Itoa(m.Support), strconv.Itoa(m.Predicted),
+				strconv.Itoa(m.TruePositives), ratio(m.Precision), ratio(m.Recall), ratio(m.F1)})
+		}
+		cw.Write([]string{"(总计)", strconv.Itoa(r.Total), strconv.Itoa(r.Total), strconv.Itoa(r.Correct),
+			"", "", ratio(r.MacroF1)})
+	case TableConfusion:
+		cw.Write(append([]string{"expected\\predicted"}, r.Labels...))
+		for i, label := range r.Labels {
+			row := []string{label}
+			for _, n := range r.Confusion[i] {
+				row = append(row, strconv.Itoa(n))
+			}
+			cw.Write(row)
+		}
+	case TableMisclassified:
+		cw.Write([]string{"path", "expected", "predicted", "classifier", "confidence", "reason"})
+		for _, o := range r.Misclassified {
+			cw.Write([]string{o.Path, o.Expected, o.Predicted, o.Classifier, ratio(o.Confidence), o.Reason})
+		}
+	default:
+		return fmt.Errorf("未知的表格: %s", table)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Disagreement 两个配置给出不同结果的文件
+type Disagreement struct {
+	Path      string `json:"path"`
+	Expected  string `json:"expected"`
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+}
+
+// Comparison 两个流水线配置在同一语料上的对比
+type Comparison struct {
+	Baseline      *Report        `json:"baseline"`
+	Candidate     *Report        `json:"candidate"`
+	AccuracyDelta float64        `json:"accuracyDelta"`
+	MacroF1Delta  float64        `json:"macroF1Delta"`
+	Fixed         int            `json:"fixed"`     // 基线分错、候选分对的文件数
+	Regressed     int            `json:"regressed"` // 基线分对、候选分错的文件数
+	Disagreements []Disagreement `json:"disagreements"`
+}
+
+// Compare 对比两次在同一语料上的评估
+func Compare(baseline, candidate *Report) *Comparison {
+	c := &Comparison{
+		Baseline:      baseline,
+		Candidate:     candidate,
+		AccuracyDelta: candidate.Accuracy - baseline.Accuracy,
+		MacroF1Delta:  candidate.MacroF1 - baseline.MacroF1,
+		Disagreements: []Disagreement{},
+	}
+	predicted := make(map[string]string, len(candidate.outcomes))
+	for _, o := range candidate.outcomes {
+		predicted[o.Path] = o.Predicted
+	}
+	for _, o := range baseline.outcomes {
+		other, ok := predicted[o.Path]
+		if !ok || other == o.Predicted {
+			continue
+		}
+		c.Disagreements = append(c.Disagreements, Disagreement{Path: o.Path, Expected: o.Expected, Baseline: o.Predicted, Candidate: other})
+		switch o.Expected {
+		case other:
+			c.Fixed++
+		case o.Predicted:
+			c.Regressed++
+		}
+	}
+	return c
+}
+
+// WriteCSV 以 CSV 格式写出对比表；table 为 comparison 时逐分类对比两个配置，其他表格写出候选配置的结果
+func (c *Comparison) WriteCSV(w io.Writer, table string) error {
+	if table != TableComparison {
+		return c.Candidate.WriteCSV(w, table)
+	}
+
+	seen := make(map[string]bool)
+	var labels []string
+	for _, label := range append(append([]string{}, c.Baseline.Labels...), c.Candidate.Labels...) {
+		if !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"category", "support",
+		c.Baseline.Name + "_precision", c.Baseline.Name + "_recall", c.Baseline.Name + "_f1",
+		c.Candidate.Name + "_precision", c.Candidate.Name + "_recall", c.Candidate.Name + "_f1", "f1_delta"})
+	for _, label := range labels {
+		a, b := c.Baseline.metrics(label), c.Candidate.metrics(label)
+		cw.Write([]string{label, strconv.Itoa(a.Support),
+			ratio(a.Precision), ratio(a.Recall), ratio(a.F1),
+			ratio(b.Precision), ratio(b.Recall), ratio(b.F1), ratio(b.F1 - a.F1)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func ratio(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
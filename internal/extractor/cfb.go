@@ -0,0 +1,423 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// OLE2 复合文档（Compound File Binary，旧版 Office 的 .doc/.xls/.ppt 容器）只读解析：
+// 文件由定长扇区组成，扇区链记录在 FAT 中；目录项组成红黑树，
+// 小于 4096 字节的流存放在迷你流中，按 64 字节的迷你扇区和迷你 FAT 寻址。
+
+// 特殊扇区号
+const (
+	cfbMaxRegSect  = 0xFFFFFFFA
+	cfbEndOfChain  = 0xFFFFFFFE
+	cfbNoStream    = 0xFFFFFFFF
+	cfbHeaderSize  = 512
+	cfbDirEntrySz  = 128
+	cfbMaxStreamSz = 256 << 20 // 单个流的大小上限，防止损坏的文件耗尽内存
+)
+
+// 目录项类型
+const (
+	cfbTypeStorage = 1
+	cfbTypeStream  = 2
+	cfbTypeRoot    = 5
+)
+
+var cfbSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// errNotCFB 文件不是 OLE2 复合文档
+var errNotCFB = errors.New("不是有效的 OLE2 复合文档")
+
+// cfbEntry 目录项
+type cfbEntry struct {
+	Name  string
+	Type  byte
+	left  uint32
+	right uint32
+	child uint32
+	start uint32
+	Size  uint64
+}
+
+// cfbFile 打开的复合文档
+type cfbFile struct {
+	r          io.ReaderAt
+	size       int64
+	sectorSize int
+	miniSize   int
+	miniCutoff uint64
+	fat        []uint32
+	miniFAT    []uint32
+	entries    []cfbEntry
+	miniStream []byte
+}
+
+// openCFB 打开复合文档，调用方需关闭返回的文件
+func openCFB(path string) (*cfbFile, *os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+	cf, err := readCFB(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return cf, f, nil
+}
+
+// readCFB 读取文件头、FAT、迷你 FAT 和目录
+func readCFB(r io.ReaderAt, size int64) (*cfbFile, error) {
+	header := make([]byte, cfbHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil || !bytes.Equal(header[:8], cfbSignature) {
+		return nil, errNotCFB
+	}
+	le := binary.LittleEndian
+	sectorShift := le.Uint16(header[0x1E:])
+	miniShift := le.Uint16(header[0x20:])
+	if sectorShift != 9 && sectorShift != 12 || miniShift != 6 {
+		return nil, fmt.Errorf("复合文档扇区大小无效")
+	}
+	cf := &cfbFile{
+		r:          r,
+		size:       size,
+		sectorSize: 1 << sectorShift,
+		miniSize:   1 << miniShift,
+		miniCutoff: uint64(le.Uint32(header[0x38:])),
+	}
+	numFAT := le.Uint32(header[0x2C:])
+	dirStart := le.Uint32(header[0x30:])
+	miniFATStart := le.Uint32(header[0x3C:])
+	difatStart := le.Uint32(header[0x44:])
+	numDIFAT := le.Uint32(header[0x48:])
+
+	// DIFAT：文件头中的 109 项，之后链接到 DIFAT 扇区
+	var fatSectors []uint32
+	for i := 0; i < 109; i++ {
+		if s := le.Uint32(header[0x4C+4*i:]); s <= cfbMaxRegSect {
+			fatSectors = append(fatSectors, s)
+		}
+	}
+	// DIFAT 扇区数和 FAT 扇区数都不会超过文件中的扇区总数；DIFAT 链出现环时报错
+	totalSectors := uint32((size + int64(cf.sectorSize) - 1) / int64(cf.sectorSize))
+	if numDIFAT > totalSectors {
+		numDIFAT = totalSectors
+	}
+	if numFAT > totalSectors {
+		numFAT = totalSectors
+	}
+	perSector := cf.sectorSize/4 - 1
+	sector := difatStart
+	visited := make(map[uint32]bool)
+	for i := uint32(0); i < numDIFAT && sector <= cfbMaxRegSect && uint32(len(fatSectors)) < numFAT; i++ {
+		if visited[sector] {
+			return nil, fmt.Errorf("DIFAT 扇区链损坏")
+		}
+		visited[sector] = true
+		buf, err := cf.readSector(sector)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < perSector; j++ {
+			if s := le.Uint32(buf[4*j:]); s <= cfbMaxRegSect {
+				fatSectors = append(fatSectors, s)
+			}
+		}
+		sector = le.Uint32(buf[4*perSector:])
+	}
+	if uint32(len(fatSectors)) > numFAT {
+		fatSectors = fatSectors[:numFAT]
+	}
+
+	for _, s := range fatSectors {
+		buf, err := cf.readSector(s)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j+4 <= len(buf); j += 4 {
+			cf.fat = append(cf.fat, le.Uint32(buf[j:]))
+		}
+	}
+
+	// 目录
+	dir, err := cf.readChain(dirStart, 0, cf.fat, cf.readSector)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %v", err)
+	}
+	for off := 0; off+cfbDirEntrySz <= len(dir); off += cfbDirEntrySz {
+		e := parseCFBEntry(dir[off : off+cfbDirEntrySz])
+		if cf.sectorSize == 512 {
+			// 第 3 版文件的流大小只有低 32 位有效
+			e.Size &= 0xFFFFFFFF
+		}
+		cf.entries = append(cf.entries, e)
+	}
+	if len(cf.entries) == 0 || cf.entries[0].Type != cfbTypeRoot {
+		return nil, fmt.Errorf("复合文档缺少根目录")
+	}
+
+	// 迷你 FAT 和迷你流（迷你流是根目录项指向的普通流）
+	if miniFATStart <= cfbMaxRegSect {
+		buf, err := cf.readChain(miniFATStart, 0, cf.fat, cf.readSector)
+		if err != nil {
+			return nil, fmt.Errorf("读取迷你 FAT 失败: %v", err)
+		}
+		for j := 0; j+4 <= len(buf); j += 4 {
+			cf.miniFAT = append(cf.miniFAT, le.Uint32(buf[j:]))
+		}
+		root := cf.entries[0]
+		if root.start <= cfbMaxRegSect && root.Size > 0 {
+			cf.miniStream, err = cf.readChain(root.start, root.Size, cf.fat, cf.readSector)
+			if err != nil {
+				return nil, fmt.Errorf("读取迷你流失败: %v", err)
+			}
+		}
+	}
+	return cf, nil
+}
+
+func parseCFBEntry(b []byte) cfbEntry {
+	le := binary.LittleEndian
+	nameLen := int(le.Uint16(b[0x40:]))
+	if nameLen > 64 {
+		nameLen = 64
+	}
+	units := make([]uint16, 0, nameLen/2)
+	for i := 0; i+1 < nameLen; i += 2 {
+		if u := le.Uint16(b[i:]); u != 0 {
+			units = append(units, u)
+		}
+	}
+	return cfbEntry{
+		Name:  string(utf16.Decode(units)),
+		Type:  b[0x42],
+		left:  le.Uint32(b[0x44:]),
+		right: le.Uint32(b[0x48:]),
+		child: le.Uint32(b[0x4C:]),
+		start: le.Uint32(b[0x74:]),
+		Size:  le.Uint64(b[0x78:]),
+	}
+}
+
+// readSector 读取普通扇区
+func (cf *cfbFile) readSector(sector uint32) ([]byte, error) {
+	off := (int64(sector) + 1) * int64(cf.sectorSize)
+	if off >= cf.size {
+		return nil, fmt.Errorf("扇区 %d 超出文件范围", sector)
+	}
+	buf := make([]byte, cf.sectorSize)
+	n, err := cf.r.ReadAt(buf, off)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	// 最后一个扇区可能不完整，不足部分补零
+	for i := n; i < len(buf); i++ {
+		buf[i] = 0
+	}
+	return buf, nil
+}
+
+// readMiniSector 读取迷你扇区
+func (cf *cfbFile) readMiniSector(sector uint32) ([]byte, error) {
+	off := int(sector) * cf.miniSize
+	if off+cf.miniSize > len(cf.miniStream) {
+		return nil, fmt.Errorf("迷你扇区 %d 超出迷你流范围", sector)
+	}
+	return cf.miniStream[off : off+cf.miniSize], nil
+}
+
+// readChain 沿扇区链读取数据；size 为 0 时读到链尾
+func (cf *cfbFile) readChain(start uint32, size uint64, table []uint32, read func(uint32) ([]byte, error)) ([]byte, error) {
+	if size > cfbMaxStreamSz {
+		return nil, fmt.Errorf("流过大: %d 字节", size)
+	}
+	var out bytes.Buffer
+	sector := start
+	// 同一扇区在链中出现两次说明存在环
+	visited := make(map[uint32]bool)
+	for sector != cfbEndOfChain {
+		if sector > cfbMaxRegSect || int(sector) >= len(table) || visited[sector] {
+			return nil, fmt.Errorf("扇区链损坏")
+		}
+		visited[sector] = true
+		buf, err := read(sector)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(buf)
+		if (size > 0 && uint64(out.Len()) >= size) || out.Len() > cfbMaxStreamSz {
+			break
+		}
+		sector = table[sector]
+	}
+	if size > 0 && uint64(out.Len()) < size {
+		return nil, fmt.Errorf("流数据不完整")
+	}
+	data := out.Bytes()
+	if size > 0 && uint64(len(data)) > size {
+		data = data[:size]
+	}
+	return data, nil
+}
+
+// children 列出存储（storage）下的直接子项，按目录树的中序排列
+func (cf *cfbFile) children(parent int) []int {
+	var result []int
+	visited := make(map[uint32]bool)
+	var walk func(id uint32)
+	walk = func(id uint32) {
+		if id == cfbNoStream || int(id) >= len(cf.entries) || visited[id] {
+			return
+		}
+		visited[id] = true
+		e := cf.entries[id]
+		walk(e.left)
+		result = append(result, int(id))
+		walk(e.right)
+	}
+	walk(cf.entries[parent].child)
+	return result
+}
+
+// find 在根存储下按名称查找流（不区分大小写）
+func (cf *cfbFile) find(name string) (cfbEntry, bool) {
+	for _, id := range cf.children(0) {
+		if e := cf.entries[id]; e.Type == cfbTypeStream && strings.EqualFold(e.Name, name) {
+			return e, true
+		}
+	}
+	return cfbEntry{}, false
+}
+
+// Stream 读取根存储下指定名称的流
+func (cf *cfbFile) Stream(name string) ([]byte, error) {
+	e, ok := cf.find(name)
+	if !ok {
+		return nil, fmt.Errorf("未找到流 %s", name)
+	}
+	if e.Size == 0 {
+		return []byte{}, nil
+	}
+	if e.Size < cf.miniCutoff {
+		return cf.readChain(e.start, e.Size, cf.miniFAT, cf.readMiniSector)
+	}
+	return cf.readChain(e.start, e.Size, cf.fat, cf.readSector)
+}
+
+// Has 根存储下是否存在指定名称的流
+func (cf *cfbFile) Has(name string) bool {
+	_, ok := cf.find(name)
+	return ok
+}
+
+// 摘要信息属性
+const (
+	cfbPIDCodepage = 1
+	cfbPIDTitle    = 2
+	cfbPIDSubject  = 3
+	cfbPIDAuthor   = 4
+	cfbPIDKeywords = 5
+)
+
+// cfbSummaryKeys 摘要信息属性对应的元数据键名，与 OOXML 的核心属性一致
+var cfbSummaryKeys = map[uint32]string{
+	cfbPIDTitle:    "title",
+	cfbPIDSubject:  "subject",
+	cfbPIDAuthor:   "author",
+	cfbPIDKeywords: "keywords",
+}
+
+// cfbMetadata 读取复合文档 \x05SummaryInformation 流中的标题、作者等属性
+func cfbMetadata(path string) (map[string]string, error) {
+	cf, f, err := openCFB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make(map[string]string)
+	data, err := cf.Stream("\x05SummaryInformation")
+	if err != nil || len(data) < 48 {
+		return result, nil
+	}
+	le := binary.LittleEndian
+	// 属性集头 28 字节，之后为第一节的 FMTID（16 字节）和偏移
+	section := int(le.Uint32(data[44:]))
+	if section+8 > len(data) {
+		return result, nil
+	}
+	count := int(le.Uint32(data[section+4:]))
+	type property struct {
+		id     uint32
+		offset int
+	}
+	var props []property
+	codepage := 1252
+	for i := 0; i < count && section+8+8*i+8 <= len(data); i++ {
+		id := le.Uint32(data[section+8+8*i:])
+		offset := section + int(le.Uint32(data[section+12+8*i:]))
+		if offset+8 > len(data) {
+			continue
+		}
+		if id == cfbPIDCodepage && le.Uint32(data[offset:]) == 2 { // VT_I2
+			codepage = int(le.Uint16(data[offset+4:]))
+			continue
+		}
+		props = append(props, property{id, offset})
+	}
+	for _, p := range props {
+		key, ok := cfbSummaryKeys[p.id]
+		if !ok || le.Uint32(data[p.offset:]) != 0x1E { // VT_LPSTR
+			continue
+		}
+		n := int(le.Uint32(data[p.offset+4:]))
+		start := p.offset + 8
+		if n <= 0 || start+n > len(data) {
+			continue
+		}
+		if text := strings.TrimSpace(decodeCodepage(data[start:start+n], codepage)); text != "" {
+			result[key] = text
+		}
+	}
+	return result, nil
+}
+
+// decodeCodepage 按 Windows 代码页解码字节串，去掉结尾的空字符
+func decodeCodepage(raw []byte, codepage int) string {
+	switch codepage {
+	case 1200: // UTF-16LE
+		return strings.TrimRight(utf16LE(raw), "\x00")
+	case 65001:
+		return strings.TrimRight(string(raw), "\x00")
+	}
+	raw = bytes.TrimRight(raw, "\x00")
+	if enc := codepageEncoding(codepage); enc != nil {
+		if decoded, err := enc.NewDecoder().Bytes(raw); err == nil {
+			return string(decoded)
+		}
+	}
+	return string(raw)
+}
+
+// utf16LE 解码 UTF-16LE 字节串
+func utf16LE(raw []byte) string {
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(raw[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
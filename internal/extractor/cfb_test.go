@@ -0,0 +1,257 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// cfbStream 测试用复合文档中的一个流
+type cfbStream struct {
+	name string
+	data []byte
+}
+
+// buildCFB 生成第 3 版（512 字节扇区）复合文档；迷你流阈值为 0，所有流都存放在普通扇区中
+func buildCFB(streams ...cfbStream) []byte {
+	const sectorSize = 512
+	le := binary.LittleEndian
+	var (
+		sectors [][]byte
+		fat     []uint32
+	)
+	alloc := func(data []byte) uint32 {
+		n := (len(data) + sectorSize - 1) / sectorSize
+		if n == 0 {
+			return cfbEndOfChain
+		}
+		start := uint32(len(sectors))
+		for i := 0; i < n; i++ {
+			sector := make([]byte, sectorSize)
+			copy(sector, data[i*sectorSize:])
+			sectors = append(sectors, sector)
+			next := uint32(cfbEndOfChain)
+			if i < n-1 {
+				next = start + uint32(i) + 1
+			}
+			fat = append(fat, next)
+		}
+		return start
+	}
+	entry := func(name string, typ byte, right, child, start uint32, size int) []byte {
+		b := make([]byte, cfbDirEntrySz)
+		units := utf16.Encode([]rune(name))
+		for i, u := range units {
+			le.PutUint16(b[2*i:], u)
+		}
+		le.PutUint16(b[0x40:], uint16(2*len(units)+2))
+		b[0x42], b[0x43] = typ, 1
+		le.PutUint32(b[0x44:], cfbNoStream)
+		le.PutUint32(b[0x48:], right)
+		le.PutUint32(b[0x4C:], child)
+		le.PutUint32(b[0x74:], start)
+		le.PutUint64(b[0x78:], uint64(size))
+		return b
+	}
+
+	child := uint32(cfbNoStream)
+	if len(streams) > 0 {
+		child = 1
+	}
+	dir := entry("Root Entry", cfbTypeRoot, cfbNoStream, child, cfbEndOfChain, 0)
+	for i, s := range streams {
+		right := uint32(cfbNoStream)
+		if i+1 < len(streams) {
+			right = uint32(i + 2)
+		}
+		dir = append(dir, entry(s.name, cfbTypeStream, right, cfbNoStream, alloc(s.data), len(s.data))...)
+	}
+	dirStart := alloc(dir)
+
+	// FAT 扇区放在最后，自身在 FAT 中标记为 0xFFFFFFFD
+	perSector := sectorSize / 4
+	numFAT := 1
+	for len(sectors)+numFAT > numFAT*perSector {
+		numFAT++
+	}
+	fatStart := len(sectors)
+	for i := 0; i < numFAT; i++ {
+		fat = append(fat, 0xFFFFFFFD)
+		sectors = append(sectors, nil)
+	}
+	for len(fat) < numFAT*perSector {
+		fat = append(fat, cfbNoStream)
+	}
+	for i := 0; i < numFAT; i++ {
+		sector := make([]byte, sectorSize)
+		for j := 0; j < perSector; j++ {
+			le.PutUint32(sector[4*j:], fat[i*perSector+j])
+		}
+		sectors[fatStart+i] = sector
+	}
+
+	header := make([]byte, cfbHeaderSize)
+	copy(header, cfbSignature)
+	le.PutUint16(header[0x18:], 0x3E)
+	le.PutUint16(header[0x1A:], 3)
+	le.PutUint16(header[0x1C:], 0xFFFE)
+	le.PutUint16(header[0x1E:], 9)
+	le.PutUint16(header[0x20:], 6)
+	le.PutUint32(header[0x2C:], uint32(numFAT))
+	le.PutUint32(header[0x30:], dirStart)
+	le.PutUint32(header[0x3C:], cfbEndOfChain)
+	le.PutUint32(header[0x44:], cfbEndOfChain)
+	for i := 0; i < 109; i++ {
+		s := uint32(cfbNoStream)
+		if i < numFAT {
+			s = uint32(fatStart + i)
+		}
+		le.PutUint32(header[0x4C+4*i:], s)
+	}
+
+	out := header
+	for _, s := range sectors {
+		out = append(out, s...)
+	}
+	return out
+}
+
+// writeTemp 将数据写入测试临时目录中的文件，返回路径
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCFBStreams(t *testing.T) {
+	big := make([]byte, 1500)
+	for i := range big {
+		big[i] = byte(i)
+	}
+	path := writeTemp(t, "a.cfb", buildCFB(
+		cfbStream{"Small", []byte("hello")},
+		cfbStream{"Big", big},
+		cfbStream{"Empty", nil},
+	))
+	cf, f, err := openCFB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tests := []struct {
+		name string
+		want []byte
+	}{
+		{"Small", []byte("hello")},
+		{"big", big}, // 名称不区分大小写
+		{"Empty", []byte{}},
+	}
+	for _, tt := range tests {
+		got, err := cf.Stream(tt.name)
+		if err != nil {
+			t.Errorf("Stream(%q): %v", tt.name, err)
+			continue
+		}
+		if string(got) != string(tt.want) {
+			t.Errorf("Stream(%q) = %d 字节，期望 %d 字节", tt.name, len(got), len(tt.want))
+		}
+	}
+	if _, err := cf.Stream("Missing"); err == nil {
+		t.Error("Stream(\"Missing\") 应返回错误")
+	}
+}
+
+// setFAT 修改 buildCFB 生成的文件中扇区 sector 的下一扇区（FAT 位于最后一个扇区）
+func setFAT(data []byte, sector, next uint32) {
+	fat := len(data) - 512
+	binary.LittleEndian.PutUint32(data[fat+4*int(sector):], next)
+}
+
+func TestCFBLoopedChain(t *testing.T) {
+	// buildCFB 按顺序分配扇区：Small 为扇区 0，Big 为扇区 1-3，目录为扇区 4
+	build := func() []byte {
+		return buildCFB(cfbStream{"Small", []byte("hello")}, cfbStream{"Big", make([]byte, 1500)})
+	}
+	tests := []struct {
+		name         string
+		sector, next uint32
+		openErr      bool
+	}{
+		{"流指向自身", 1, 1, false},
+		{"流指回起始扇区", 2, 1, false},
+		{"目录指向自身", 4, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := build()
+			setFAT(data, tt.sector, tt.next)
+			cf, f, err := openCFB(writeTemp(t, "a.cfb", data))
+			if tt.openErr {
+				if err == nil {
+					f.Close()
+					t.Fatal("openCFB 应返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if got, err := cf.Stream("Big"); err == nil {
+				t.Errorf("Stream(\"Big\") 返回 %d 字节，期望返回错误", len(got))
+			}
+		})
+	}
+}
+
+func TestCFBLoopedDIFAT(t *testing.T) {
+	// 扇区 0 作为 DIFAT 扇区：各项均为空，最后一项（下一个 DIFAT 扇区）指向自身
+	difat := bytes.Repeat([]byte{0xFF}, 512)
+	binary.LittleEndian.PutUint32(difat[508:], 0)
+	data := buildCFB(cfbStream{"Small", difat})
+	le := binary.LittleEndian
+	le.PutUint32(data[0x2C:], 1000)       // FAT 扇区数
+	le.PutUint32(data[0x44:], 0)          // 第一个 DIFAT 扇区
+	le.PutUint32(data[0x48:], 0xFFFFFFFF) // DIFAT 扇区数
+
+	_, f, err := openCFB(writeTemp(t, "a.cfb", data))
+	if err == nil {
+		f.Close()
+		t.Error("openCFB 应返回错误")
+	}
+}
+
+func TestOLE2Truncated(t *testing.T) {
+	word, table := docStreams(-1, docPiece{"Hello\r", true})
+	tests := []struct {
+		name      string
+		extractor TextExtractor
+		data      []byte
+	}{
+		{"doc", &docExtractor{}, buildCFB(cfbStream{"WordDocument", word}, cfbStream{"1Table", table})},
+		{"xls", &xlsExtractor{}, buildCFB(cfbStream{"Workbook", sampleXLS(biffBIFF8, false)})},
+		{"ppt", &pptExtractor{}, buildCFB(cfbStream{"PowerPoint Document", samplePPT()})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// FAT 位于最后一个扇区，截断到它之前的任意位置都应返回错误
+			for _, n := range []int{0, 8, 100, cfbHeaderSize, len(tt.data) / 2, len(tt.data) - 512} {
+				path := writeTemp(t, "a."+tt.name, tt.data[:n])
+				if got, err := tt.extractor.Extract(path); err == nil {
+					t.Errorf("截断到 %d 字节: Extract() = %q，期望返回错误", n, got)
+				}
+			}
+			// 截断在 FAT 中时结果不确定，只要求不崩溃
+			for _, n := range []int{len(tt.data) - 256, len(tt.data) - 1} {
+				tt.extractor.Extract(writeTemp(t, "a."+tt.name, tt.data[:n]))
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Word 97-2003 文档：正文按片段表（piece table）存放在 WordDocument 流中，
+// 片段表位于 0Table 或 1Table 流的 Clx 结构里，每个片段为 UTF-16LE 或压缩的 8 位文本
+
+// FIB（文件信息块）中用到的字段偏移
+const (
+	docFibIdent    = 0x0000
+	docFibFlags    = 0x000A
+	docFibCcpText  = 0x004C // 正文字符数
+	docFibFcClx    = 0x01A2
+	docFibLcbClx   = 0x01A6
+	docWordIdent   = 0xA5EC
+	docFlagTable1  = 0x0200 // 片段表位于 1Table 流
+	docFlagCrypted = 0x0100
+	docPieceSize   = 8
+)
+
+// docExtractor 尽力提取 .doc 的正文；页眉页脚、脚注和批注不输出，域代码只保留显示结果
+type docExtractor struct{}
+
+func (d *docExtractor) Extract(path string) (string, error) {
+	// 部分软件将 RTF 文档保存为 .doc 扩展名
+	if isRTFFile(path) {
+		return (&rtfExtractor{}).Extract(path)
+	}
+	cf, f, err := openCFB(path)
+	if err != nil {
+		return "", fmt.Errorf("打开 doc 失败: %v", err)
+	}
+	defer f.Close()
+
+	word, err := cf.Stream("WordDocument")
+	if err != nil {
+		return "", err
+	}
+	if len(word) < docFibLcbClx+4 {
+		return "", fmt.Errorf("WordDocument 流过短")
+	}
+	le := binary.LittleEndian
+	if le.Uint16(word[docFibIdent:]) != docWordIdent {
+		return "", fmt.Errorf("不支持的 Word 版本")
+	}
+	flags := le.Uint16(word[docFibFlags:])
+	if flags&docFlagCrypted != 0 {
+		return "", fmt.Errorf("文档已加密")
+	}
+	tableName := "0Table"
+	if flags&docFlagTable1 != 0 {
+		tableName = "1Table"
+	}
+	table, err := cf.Stream(tableName)
+	if err != nil {
+		return "", err
+	}
+
+	ccpText := int(le.Uint32(word[docFibCcpText:]))
+	fcClx := int(le.Uint32(word[docFibFcClx:]))
+	lcbClx := int(le.Uint32(word[docFibLcbClx:]))
+	if fcClx < 0 || lcbClx <= 0 || fcClx+lcbClx > len(table) {
+		return "", fmt.Errorf("片段表位置无效")
+	}
+	text, err := docPieces(word, table[fcClx:fcClx+lcbClx], ccpText)
+	if err != nil {
+		return "", err
+	}
+	return limitContent(strings.TrimSpace(docCleanText(text))), nil
+}
+
+// Metadata 读取摘要信息中的标题、作者等属性
+func (d *docExtractor) Metadata(path string) (map[string]string, error) {
+	if isRTFFile(path) {
+		return map[string]string{}, nil
+	}
+	return cfbMetadata(path)
+}
+
+// docPieces 按片段表拼出正文（前 ccpText 个字符）
+func docPieces(word, clx []byte, ccpText int) (string, error) {
+	le := binary.LittleEndian
+	// Clx 由若干 Prc（0x01，属性修改）和一个 Pcdt（0x02，片段表）组成
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			return "", fmt.Errorf("片段表损坏")
+		}
+		pos += 3 + int(le.Uint16(clx[pos+1:]))
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return "", fmt.Errorf("未找到片段表")
+	}
+	lcb := int(le.Uint32(clx[pos+1:]))
+	plc := clx[pos+5:]
+	if lcb > len(plc) {
+		lcb = len(plc)
+	}
+	plc = plc[:lcb]
+	// PlcPcd：n+1 个字符位置，之后为 n 个 8 字节的片段描述
+	n := (len(plc) - 4) / (4 + docPieceSize)
+	if n <= 0 {
+		return "", fmt.Errorf("片段表为空")
+	}
+
+	var b strings.Builder
+	for i := 0; i < n && b.Len() < maxContentSize*2; i++ {
+		cpStart := int(le.Uint32(plc[4*i:]))
+		cpEnd := int(le.Uint32(plc[4*(i+1):]))
+		if ccpText > 0 && cpStart >= ccpText {
+			break
+		}
+		if ccpText > 0 && cpEnd > ccpText {
+			cpEnd = ccpText
+		}
+		count := cpEnd - cpStart
+		if count <= 0 {
+			continue
+		}
+		pcd := plc[4*(n+1)+docPieceSize*i:]
+		fc := le.Uint32(pcd[2:])
+		if fc&0x40000000 != 0 {
+			// 压缩片段：每个字符一个字节（Windows-1252），偏移为实际值的两倍
+			start := int(fc&^0x40000000) / 2
+			if start+count > len(word) {
+				continue
+			}
+			decoded, err := charmap.Windows1252.NewDecoder().Bytes(word[start : start+count])
+			if err != nil {
+				continue
+			}
+			b.Write(decoded)
+		} else {
+			start := int(fc)
+			if start+2*count > len(word) {
+				continue
+			}
+			b.WriteString(utf16LE(word[start : start+2*count]))
+		}
+	}
+	return b.String(), nil
+}
+
+// docCleanText 处理 Word 的特殊字符：段落、单元格标记转为换行和制表符，
+// 域只保留结果部分（0x13 域代码 0x14 域结果 0x15），去掉图片、对象等占位符
+func docCleanText(text string) string {
+	var b strings.Builder
+	// 每层域是否处于代码部分
+	var fields []bool
+	inCode := func() bool {
+		for _, code := range fields {
+			if code {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range text {
+		switch r {
+		case 0x13:
+			fields = append(fields, true)
+			continue
+		case 0x14:
+			if n := len(fields); n > 0 {
+				fields[n-1] = false
+			}
+			continue
+		case 0x15:
+			if n := len(fields); n > 0 {
+				fields = fields[:n-1]
+			}
+			continue
+		}
+		if inCode() {
+			continue
+		}
+		switch {
+		case r == '\r' || r == 0x0B || r == 0x0C:
+			b.WriteByte('\n')
+		case r == 0x07:
+			b.WriteByte('\t')
+		case r == '\t':
+			b.WriteByte('\t')
+		case r == 0x1E:
+			b.WriteByte('-') // 不间断连字符
+		case r == 0xA0:
+			b.WriteByte(' ')
+		case r < 0x20:
+			// 图片（0x01）、绘图对象（0x08）、可选连字符（0x1F）等
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isRTFFile 文件内容是否为 RTF
+func isRTFFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 5)
+	n, _ := f.Read(head)
+	return bytes.Equal(head[:n], []byte(`{\rtf`))
+}
+
+func init() {
+	Register(".doc", &docExtractor{})
+}
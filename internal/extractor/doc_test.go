@@ -0,0 +1,120 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// docPiece 测试文档中的一个片段；compressed 为 true 时按单字节存放
+type docPiece struct {
+	text       string
+	compressed bool
+}
+
+// docStreams 生成 WordDocument 流（FIB 之后依次存放各片段）和 1Table 流（只含片段表）；
+// ccpText 小于 0 时全部片段都属于正文
+func docStreams(ccpText int, pieces ...docPiece) (word, table []byte) {
+	le := binary.LittleEndian
+	word = make([]byte, 0x200)
+	le.PutUint16(word[docFibIdent:], docWordIdent)
+	le.PutUint16(word[docFibFlags:], docFlagTable1)
+
+	cps := []uint32{0}
+	var pcds []byte
+	for _, p := range pieces {
+		pcd := make([]byte, docPieceSize)
+		units := utf16.Encode([]rune(p.text))
+		if p.compressed {
+			le.PutUint32(pcd[2:], uint32(len(word))*2|0x40000000)
+			word = append(word, p.text...)
+		} else {
+			le.PutUint32(pcd[2:], uint32(len(word)))
+			for _, u := range units {
+				word = le.AppendUint16(word, u)
+			}
+		}
+		pcds = append(pcds, pcd...)
+		cps = append(cps, cps[len(cps)-1]+uint32(len(units)))
+	}
+	if ccpText < 0 {
+		ccpText = int(cps[len(cps)-1])
+	}
+
+	var plc []byte
+	for _, cp := range cps {
+		plc = le.AppendUint32(plc, cp)
+	}
+	plc = append(plc, pcds...)
+	// Clx：一个 Prc（属性修改，内容忽略）和片段表
+	table = []byte{0x01, 0x02, 0x00, 0xAA, 0xBB, 0x02}
+	table = le.AppendUint32(table, uint32(len(plc)))
+	table = append(table, plc...)
+
+	le.PutUint32(word[docFibCcpText:], uint32(ccpText))
+	le.PutUint32(word[docFibFcClx:], 0)
+	le.PutUint32(word[docFibLcbClx:], uint32(len(table)))
+	return word, table
+}
+
+func TestDOCExtract(t *testing.T) {
+	word, table := docStreams(-1,
+		docPiece{"Hello, world\r", true},
+		docPiece{"季度报告\x07第一季度\x07\r", false},
+		docPiece{"\x13 HYPERLINK \"https://example.com\" \x14示例链接\x15结束\x01\r", false},
+	)
+	body, bodyTable := docStreams(5, docPiece{"正文内容\r", false}, docPiece{"脚注文本", false})
+	encrypted := append([]byte(nil), word...)
+	binary.LittleEndian.PutUint16(encrypted[docFibFlags:], docFlagTable1|docFlagCrypted)
+
+	tests := []struct {
+		name    string
+		streams []cfbStream
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "压缩与 Unicode 片段",
+			streams: []cfbStream{{"WordDocument", word}, {"1Table", table}},
+			want:    "Hello, world\n季度报告\t第一季度\t\n示例链接结束",
+		},
+		{
+			name:    "正文之后的脚注不输出",
+			streams: []cfbStream{{"WordDocument", body}, {"1Table", bodyTable}},
+			want:    "正文内容",
+		},
+		{
+			name:    "已加密",
+			streams: []cfbStream{{"WordDocument", encrypted}, {"1Table", table}},
+			wantErr: true,
+		},
+		{
+			name:    "缺少片段表所在的流",
+			streams: []cfbStream{{"WordDocument", word}, {"0Table", table}},
+			wantErr: true,
+		},
+		{
+			name:    "WordDocument 流过短",
+			streams: []cfbStream{{"WordDocument", word[:0x100]}, {"1Table", table}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.doc", buildCFB(tt.streams...))
+			got, err := (&docExtractor{}).Extract(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
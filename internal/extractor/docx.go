@@ -48,6 +48,11 @@ func (d *docxExtractor) Extract(path string) (string, error) {
 	return plain, nil
 }
 
+// Metadata 读取 docProps/core.xml 中的标题、作者等属性
+func (d *docxExtractor) Metadata(path string) (map[string]string, error) {
+	return ooxmlCoreProperties(path)
+}
+
 func init() {
 	Register(".docx", &docxExtractor{})
 }
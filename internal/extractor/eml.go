@@ -0,0 +1,304 @@
+package extractor
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 邮件（RFC 5322 / MIME）：信头中的非 ASCII 文本为 RFC 2047 编码字（=?GB2312?B?...?=），
+// 各部分按 Content-Transfer-Encoding（base64、quoted-printable）解码后，文本按 charset 转为 UTF-8
+
+const (
+	emlMaxPartSize = 32 << 20 // 单个正文或附件读取的最大字节数
+	emlMaxDepth    = 16       // multipart、转发邮件和附件的最大嵌套层数
+)
+
+// emlHeaders 输出的信头及其显示名称
+var emlHeaders = []struct{ key, label string }{
+	{"Subject", "主题"},
+	{"From", "发件人"},
+	{"To", "收件人"},
+	{"Cc", "抄送"},
+	{"Date", "日期"},
+}
+
+// emlWordDecoder 解码信头中的编码字，支持 GB2312/GBK、Big5 等 WHATWG 编码标签
+var emlWordDecoder = &mime.WordDecoder{
+	CharsetReader: func(label string, input io.Reader) (io.Reader, error) {
+		e := lookupCharset(label)
+		if e == nil {
+			return nil, fmt.Errorf("不支持的字符集: %s", label)
+		}
+		return e.NewDecoder().Reader(input), nil
+	},
+}
+
+// emlExtractor 提取邮件的主题、发件人等信头和正文；multipart/alternative 中优先使用纯文本正文，
+// 附件按扩展名交给对应的提取器，每个附件以「附件: 文件名」开头，不支持的附件只列出文件名
+type emlExtractor struct{}
+
+// emailWriter 逐部分写出邮件内容
+type emailWriter struct {
+	b strings.Builder
+}
+
+func (e *emlExtractor) Extract(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	w := &emailWriter{}
+	if err := w.message(f, 0); err != nil {
+		return "", err
+	}
+	return limitContent(strings.TrimSpace(w.b.String())), nil
+}
+
+// Metadata 主题作为标题，发件人作为作者，另含日期
+func (e *emlExtractor) Metadata(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	msg, err := mail.ReadMessage(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("解析邮件失败: %v", err)
+	}
+	result := make(map[string]string)
+	for key, header := range map[string]string{"title": "Subject", "author": "From", "date": "Date"} {
+		if v := decodeHeader(msg.Header.Get(header)); v != "" {
+			result[key] = v
+		}
+	}
+	return result, nil
+}
+
+// message 解析一封邮件，写出信头和正文、附件
+func (w *emailWriter) message(r io.Reader, depth int) error {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return fmt.Errorf("解析邮件失败: %v", err)
+	}
+	for _, h := range emlHeaders {
+		if v := decodeHeader(msg.Header.Get(h.key)); v != "" {
+			fmt.Fprintf(&w.b, "%s: %s\n", h.label, v)
+		}
+	}
+	w.b.WriteByte('\n')
+	w.part(textproto.MIMEHeader(msg.Header), msg.Body, depth)
+	return nil
+}
+
+// part 按类型写出邮件的一个部分
+func (w *emailWriter) part(header textproto.MIMEHeader, body io.Reader, depth int) {
+	if depth > emlMaxDepth || w.b.Len() >= maxContentSize {
+		return
+	}
+	mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+	body = transferDecode(header.Get("Content-Transfer-Encoding"), body)
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := dispParams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	name = decodeHeader(name)
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		w.multipart(mediaType, params["boundary"], body, depth+1)
+	case mediaType == "message/rfc822":
+		// 转发的邮件
+		if name != "" {
+			fmt.Fprintf(&w.b, "\n附件: %s\n", name)
+		}
+		w.message(body, depth+1)
+	case disposition == "attachment" || name != "":
+		w.attachment(name, body, depth+1)
+	case mediaType == "text/plain":
+		data, _ := io.ReadAll(io.LimitReader(body, emlMaxPartSize))
+		text := strings.ReplaceAll(decodeCharset(data, params["charset"]), "\r\n", "\n")
+		w.b.WriteString(strings.TrimSpace(text))
+		w.b.WriteByte('\n')
+	case mediaType == "text/html":
+		data, _ := io.ReadAll(io.LimitReader(body, emlMaxPartSize))
+		// 邮件声明的字符集优先于网页中的 <meta charset>
+		contentType := ""
+		if e := lookupCharset(params["charset"]); e != nil {
+			if out, err := e.NewDecoder().Bytes(data); err == nil {
+				data, contentType = out, "text/html; charset=utf-8"
+			}
+		}
+		w.b.WriteString(parseHTML(data, contentType).text)
+	}
+	// 其他类型（内嵌图片等）不输出
+}
+
+// multipart 写出 multipart 的各部分；multipart/alternative 的各部分是同一内容的不同格式，只输出一种
+func (w *emailWriter) multipart(mediaType, boundary string, body io.Reader, depth int) {
+	if boundary == "" {
+		return
+	}
+	mr := multipart.NewReader(body, boundary)
+	if mediaType != "multipart/alternative" {
+		for {
+			p, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			w.part(p.Header, p, depth)
+		}
+	}
+
+	// 优先使用纯文本，没有时使用第一个提取出文本的部分
+	var best string
+	plain := false
+	for !plain {
+		p, err := mr.NextRawPart()
+		if err != nil {
+			break
+		}
+		sub := &emailWriter{}
+		sub.part(p.Header, p, depth)
+		text := strings.TrimSpace(sub.b.String())
+		if text == "" {
+			continue
+		}
+		if mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type")); mediaType == "text/plain" {
+			best, plain = text, true
+		} else if best == "" {
+			best = text
+		}
+	}
+	if best != "" {
+		w.b.WriteString(best)
+		w.b.WriteByte('\n')
+	}
+}
+
+// attachment 写出附件：附带的邮件和邮箱在当前层数下直接解析，受 emlMaxDepth 限制；
+// 其他有对应提取器的附件写入临时文件后提取文本
+func (w *emailWriter) attachment(name string, body io.Reader, depth int) {
+	if name == "" {
+		name = "未命名"
+	}
+	fmt.Fprintf(&w.b, "\n附件: %s\n", name)
+
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case ".eml":
+		w.message(body, depth)
+		return
+	case ".mbox":
+		w.mbox(io.LimitReader(body, emlMaxPartSize), depth)
+		return
+	}
+	if _, ok := registry[ext]; !ok {
+		return
+	}
+	tmp, err := os.CreateTemp("", "attachment-*"+ext)
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, io.LimitReader(body, emlMaxPartSize))
+	tmp.Close()
+	if err != nil {
+		return
+	}
+	text, err := ExtractText(tmp.Name())
+	if err != nil {
+		return
+	}
+	w.b.WriteString(strings.TrimSpace(text))
+	w.b.WriteByte('\n')
+}
+
+// transferDecode 按 Content-Transfer-Encoding 解码；7bit、8bit 和 binary 原样返回
+func transferDecode(cte string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(cte)) {
+	case "base64":
+		// 解码器会忽略换行；遇到非法字符时保留此前已解码的内容
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// emlCharsetAliases WHATWG 编码标签之外常见的中文字符集名称（如 Python 生成的 eucgb2312_cn）
+var emlCharsetAliases = map[string]string{
+	"eucgb2312_cn": "gbk",
+	"euc-cn":       "gbk",
+	"cp936":        "gbk",
+	"ms936":        "gbk",
+	"windows-936":  "gbk",
+}
+
+// lookupCharset 按字符集名称查找编码，未知时返回 nil
+func lookupCharset(label string) encoding.Encoding {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if alias, ok := emlCharsetAliases[label]; ok {
+		label = alias
+	}
+	e, _ := charset.Lookup(label)
+	return e
+}
+
+// decodeCharset 按 charset 标签将文本转为 UTF-8；未声明或无法识别时，不是有效 UTF-8 的按 GBK 解码
+func decodeCharset(data []byte, label string) string {
+	if label != "" {
+		if e := lookupCharset(label); e != nil {
+			if out, err := e.NewDecoder().Bytes(data); err == nil {
+				return string(out)
+			}
+		}
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	if out, err := simplifiedchinese.GBK.NewDecoder().Bytes(data); err == nil {
+		return string(out)
+	}
+	return strings.ToValidUTF8(string(data), "")
+}
+
+// decodeHeader 解码信头中的编码字；未编码的 8 位信头（一般为 GBK）同样转为 UTF-8
+func decodeHeader(s string) string {
+	if s == "" {
+		return ""
+	}
+	if !utf8.ValidString(s) {
+		s = decodeCharset([]byte(s), "")
+	}
+	if decoded, err := emlWordDecoder.DecodeHeader(s); err == nil {
+		s = decoded
+	}
+	return strings.TrimSpace(s)
+}
+
+func init() {
+	Register(".eml", &emlExtractor{})
+}
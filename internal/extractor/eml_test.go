@@ -0,0 +1,155 @@
+package extractor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// gbk 将文本转为 GBK 编码
+func gbk(s string) string {
+	out, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// b64 base64 编码，用于构造 Content-Transfer-Encoding: base64 的正文和 B 编码字
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// emlLines 以 CRLF 连接各行
+func emlLines(lines ...string) string {
+	return strings.Join(lines, "\r\n")
+}
+
+func TestEMLExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		eml     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "编码字信头、纯文本优先、quoted-printable 和附件",
+			eml: emlLines(
+				"Subject: =?GB2312?B?"+b64(gbk("季度报告"))+"?=",
+				"From: =?UTF-8?Q?=E5=BC=A0=E4=B8=89?= <zhang@example.com>",
+				"To: li@example.com",
+				"MIME-Version: 1.0",
+				`Content-Type: multipart/mixed; boundary="mixed"`,
+				"",
+				"--mixed",
+				`Content-Type: multipart/alternative; boundary="alt"`,
+				"",
+				"--alt",
+				"Content-Type: text/html; charset=utf-8",
+				"",
+				"<p>网页正文</p>",
+				"--alt",
+				"Content-Type: text/plain; charset=gbk",
+				"Content-Transfer-Encoding: base64",
+				"",
+				b64(gbk("纯文本正文")),
+				"--alt--",
+				"--mixed",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Transfer-Encoding: quoted-printable",
+				"",
+				"=E7=AC=AC=E4=BA=8C=E6=AE=B5 soft=",
+				"break",
+				"--mixed",
+				"Content-Type: text/plain; charset=utf-8",
+				`Content-Disposition: attachment; filename="=?UTF-8?B?`+b64("说明.txt")+`?="`,
+				"Content-Transfer-Encoding: base64",
+				"",
+				b64("附件内容"),
+				"--mixed",
+				"Content-Type: application/octet-stream",
+				`Content-Disposition: attachment; filename="data.bin"`,
+				"",
+				"\x00\x01\x02",
+				"--mixed--",
+			),
+			want: "主题: 季度报告\n发件人: 张三 <zhang@example.com>\n收件人: li@example.com\n\n" +
+				"纯文本正文\n第二段 softbreak\n\n附件: 说明.txt\n附件内容\n\n附件: data.bin",
+		},
+		{
+			name: "未声明字符集的 8 位信头和正文按 GBK 解码",
+			eml: emlLines(
+				"Subject: "+gbk("会议"),
+				"Content-Type: text/plain",
+				"Content-Transfer-Encoding: 8bit",
+				"",
+				gbk("明天开会"),
+			),
+			want: "主题: 会议\n\n明天开会",
+		},
+		{
+			name: "只有网页正文时按邮件声明的字符集解码",
+			eml: emlLines(
+				"Subject: =?gb2312?Q?"+qEncode(gbk("周报"))+"?=",
+				"Content-Type: text/html; charset=gb2312",
+				"",
+				"<html><head><title>忽略</title><style>p{}</style></head><body><p>"+gbk("本周完成")+"</p><table><tr><td>A</td><td>B</td></tr></table></body></html>",
+			),
+			want: "主题: 周报\n\n本周完成\nA\tB",
+		},
+		{
+			name: "转发的邮件",
+			eml: emlLines(
+				"Subject: Fwd",
+				`Content-Type: multipart/mixed; boundary="b"`,
+				"",
+				"--b",
+				"Content-Type: text/plain",
+				"",
+				"见附件",
+				"--b",
+				`Content-Type: message/rfc822; name="orig.eml"`,
+				"",
+				"Subject: Original",
+				"",
+				"原始正文",
+				"--b--",
+			),
+			want: "主题: Fwd\n\n见附件\n\n附件: orig.eml\n主题: Original\n\n原始正文",
+		},
+		{
+			name:    "不是邮件",
+			eml:     "plain text",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&emlExtractor{}).Extract(writeTemp(t, "a.eml", []byte(tt.eml)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// qEncode 将每个字节编码为 =XX，用于构造 Q 编码字
+func qEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&b, "=%02X", s[i])
+	}
+	return b.String()
+}
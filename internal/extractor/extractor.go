@@ -3,7 +3,9 @@ package extractor
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // TextExtractor 定义统一的文本提取接口
@@ -17,7 +19,27 @@ type TextExtractor interface {
 	Extract(path string) (string, error)
 }
 
+// MetadataExtractor 可选接口：提取文档元数据（标题、作者等）
+// 键名统一使用小写，如 "title"、"author"、"subject"、"keywords"
+type MetadataExtractor interface {
+	Metadata(path string) (map[string]string, error)
+}
+
+// Version 提取器版本，提取逻辑变化（新增格式、修正解析）时递增，使缓存中的旧文本失效
+const Version = 6
+
+// VersionKey 提取结果的版本标识，用于缓存键：Version 加上启用的输出选项，
+// 切换选项（如 XLSX_STRUCTURED）时缓存中的旧文本同样失效
+func VersionKey() string {
+	key := strconv.Itoa(Version)
+	for _, v := range variants {
+		key += "+" + v
+	}
+	return key
+}
+
 var (
+	variants       []string      // 启用的输出选项，在 init() 中登记
 	registry                     = make(map[string]TextExtractor)
 	fallback       TextExtractor = &defaultExtractor{}
 	maxContentSize               = 10000 // 最大截取字符数
@@ -38,6 +60,29 @@ func ExtractText(path string) (string, error) {
 	return fallback.Extract(path)
 }
 
+// ExtractMetadata 提取文档元数据；对应提取器不支持时返回空表
+func ExtractMetadata(path string) (map[string]string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if extractor, ok := registry[ext]; ok {
+		if m, ok := extractor.(MetadataExtractor); ok {
+			return m.Metadata(path)
+		}
+	}
+	return map[string]string{}, nil
+}
+
+// limitContent 将内容截断到 maxContentSize 字节以内，不截断多字节字符
+func limitContent(s string) string {
+	if len(s) <= maxContentSize {
+		return s
+	}
+	end := maxContentSize
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
 // ------- 默认提取器 -------
 
 type defaultExtractor struct{}
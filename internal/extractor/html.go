@@ -0,0 +1,261 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// htmlMaxSize 读取网页的最大字节数
+const htmlMaxSize = 8 << 20
+
+// htmlExtractor 提取网页的标题和可见文本：脚本、样式等不显示的内容不输出，
+// 块级元素各占一行，表格单元格以制表符分隔；编码依次按 BOM、<meta charset> 判断，
+// 都没有时按 UTF-8 解码，不是有效 UTF-8 的按 GBK 解码
+type htmlExtractor struct{}
+
+// htmlDocument 网页的解析结果
+type htmlDocument struct {
+	title string
+	text  string
+	meta  map[string]string // <meta name=...> 中的作者、描述、关键词
+}
+
+// htmlSkipElements 内容不显示的元素，整个跳过
+var htmlSkipElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Iframe: true,
+}
+
+// htmlBlockElements 前后换行的块级元素
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Hr: true, atom.Li: true, atom.Tr: true,
+	atom.Table: true, atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true, atom.Nav: true,
+	atom.Aside: true, atom.Main: true, atom.Blockquote: true, atom.Pre: true, atom.Form: true,
+	atom.Fieldset: true, atom.Figure: true, atom.Figcaption: true, atom.Caption: true,
+	atom.Address: true, atom.Body: true,
+}
+
+func (h *htmlExtractor) Extract(path string) (string, error) {
+	doc, err := htmlFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := doc.text
+	if doc.title != "" {
+		text = doc.title + "\n" + text
+	}
+	return limitContent(strings.TrimSpace(text)), nil
+}
+
+// Metadata 读取 <title> 和 <meta name="author|description|keywords">
+func (h *htmlExtractor) Metadata(path string) (map[string]string, error) {
+	doc, err := htmlFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := doc.meta
+	if doc.title != "" {
+		result["title"] = doc.title
+	}
+	return result, nil
+}
+
+func htmlFile(path string) (*htmlDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, htmlMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	return parseHTML(data, ""), nil
+}
+
+// parseHTML 解析网页，contentType 为外部给出的类型（如邮件中的 Content-Type），其中的 charset 优先
+func parseHTML(data []byte, contentType string) *htmlDocument {
+	e, name, certain := charset.DetermineEncoding(data, contentType)
+	if !certain && name == "windows-1252" {
+		// 未声明编码：DetermineEncoding 默认按 Windows-1252，中文网页一般为 UTF-8 或 GBK
+		if utf8.Valid(data) {
+			e = nil
+		} else {
+			e = simplifiedchinese.GBK
+		}
+	}
+	var r io.Reader = bytes.NewReader(data)
+	if e != nil {
+		r = e.NewDecoder().Reader(r)
+	}
+
+	doc := &htmlDocument{meta: make(map[string]string)}
+	var (
+		b      strings.Builder
+		title  strings.Builder
+		skip   int                   // 位于跳过的元素中（嵌套深度）
+		opened = map[atom.Atom]int{} // 各跳过元素已打开的层数
+		pre    int
+	)
+	inTitle := false
+	z := html.NewTokenizer(r)
+	for b.Len() < maxContentSize*2 {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.DataAtom {
+			case atom.Title:
+				// SVG 中的 <title> 是图形的提示文本，不是网页标题
+				inTitle = tt == html.StartTagToken && skip == 0
+				continue
+			case atom.Meta:
+				htmlMeta(tok, doc.meta)
+				continue
+			}
+			if htmlSkipElements[tok.DataAtom] {
+				if tt == html.StartTagToken {
+					skip++
+					opened[tok.DataAtom]++
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			switch {
+			case tok.DataAtom == atom.Td || tok.DataAtom == atom.Th:
+				b.WriteByte('\t')
+			case tok.DataAtom == atom.Img:
+				if alt := htmlAttr(tok, "alt"); alt != "" {
+					b.WriteString(" " + alt + " ")
+				}
+			case htmlBlockElements[tok.DataAtom]:
+				b.WriteByte('\n')
+			}
+			if tok.DataAtom == atom.Pre && tt == html.StartTagToken {
+				pre++
+			}
+		case html.EndTagToken:
+			if tok.DataAtom == atom.Title {
+				inTitle = false
+				continue
+			}
+			if htmlSkipElements[tok.DataAtom] {
+				// 只关闭本元素打开的层数，忽略多余的结束标签
+				if opened[tok.DataAtom] > 0 {
+					opened[tok.DataAtom]--
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if tok.DataAtom == atom.Pre && pre > 0 {
+				pre--
+			}
+			if htmlBlockElements[tok.DataAtom] {
+				b.WriteByte('\n')
+			}
+		case html.TextToken:
+			if inTitle {
+				title.WriteString(tok.Data)
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if pre > 0 {
+				b.WriteString(tok.Data)
+			} else {
+				b.WriteString(collapseSpaces(tok.Data))
+			}
+		}
+	}
+	doc.title = strings.TrimSpace(collapseSpaces(title.String()))
+	doc.text = cleanLines(b.String())
+	return doc
+}
+
+// htmlMeta 记录 <meta name="..." content="..."> 中的作者、描述和关键词
+func htmlMeta(tok html.Token, meta map[string]string) {
+	content := strings.TrimSpace(htmlAttr(tok, "content"))
+	if content == "" {
+		return
+	}
+	switch name := strings.ToLower(htmlAttr(tok, "name")); name {
+	case "author", "description", "keywords":
+		meta[name] = content
+	}
+}
+
+func htmlAttr(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// collapseSpaces 将连续的空白（含换行）合并为一个空格，首尾的空白同样保留一个，以免与相邻元素的文本粘连
+func collapseSpaces(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == 0xA0 {
+			space = true
+			continue
+		}
+		if r == 0xFEFF || r == 0x200B {
+			// 字节顺序标记和零宽空格
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// cleanLines 去掉每行首尾的空白和空行
+func cleanLines(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func init() {
+	h := &htmlExtractor{}
+	for _, ext := range []string{".html", ".htm", ".xhtml"} {
+		Register(ext, h)
+	}
+}
@@ -0,0 +1,47 @@
+package extractor
+
+import "testing"
+
+func TestHTMLExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "标题、块级元素和表格",
+			html: `<!DOCTYPE html><html><head><title> 季度
+报告 </title><style>p { color: red }</style><script>var a = "<p>x</p>";</script></head>
+<body><h1>概述</h1><p>第一段   文字<br>换行</p><svg><title>图形</title><text>忽略</text></svg>
+<table><tr><th>名称</th><th>数量</th></tr><tr><td>A</td><td>1</td></tr></table>
+<img src="a.png" alt="示意图"><noscript>请启用脚本</noscript></body></html>`,
+			want: "季度 报告\n概述\n第一段 文字\n换行\n名称\t数量\nA\t1\n示意图",
+		},
+		{
+			name: "预格式文本保留空白",
+			html: "<body><pre>a  b\n  c</pre><p>d</p></body>",
+			want: "a  b\nc\nd",
+		},
+		{
+			name: "按 meta 声明的 GBK 解码",
+			html: `<html><head><meta charset="gbk"></head><body><p>` + gbk("中文网页") + `</p></body></html>`,
+			want: "中文网页",
+		},
+		{
+			name: "未声明编码且不是 UTF-8 时按 GBK 解码",
+			html: "<p>" + gbk("会议纪要") + "</p>",
+			want: "会议纪要",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&htmlExtractor{}).Extract(writeTemp(t, "a.html", []byte(tt.html)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// mboxExtractor 将 mbox 邮箱拆分为单封邮件后逐封提取，每封以「邮件 N」开头，内容达到上限后停止
+type mboxExtractor struct{}
+
+func (m *mboxExtractor) Extract(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer f.Close()
+
+	w := &emailWriter{}
+	count, err := w.mbox(f, 0)
+	if err != nil {
+		return "", fmt.Errorf("读取邮箱失败: %v", err)
+	}
+	if count == 0 {
+		return "", fmt.Errorf("不是有效的 mbox 文件")
+	}
+	return limitContent(strings.TrimSpace(w.b.String())), nil
+}
+
+// mbox 逐封写出邮箱中的邮件，每封以「邮件 N」开头，内容达到上限后停止；返回邮件数。
+// 作为附件的邮箱也在这里解析，其中的邮件沿用附件所在的层数
+func (w *emailWriter) mbox(r io.Reader, depth int) (int, error) {
+	count := 0
+	err := splitMbox(r, func(msg []byte) bool {
+		count++
+		sub := &emailWriter{}
+		if err := sub.message(bytes.NewReader(msg), depth); err != nil {
+			return true
+		}
+		fmt.Fprintf(&w.b, "邮件 %d\n%s\n\n", count, strings.TrimSpace(sub.b.String()))
+		return w.b.Len() < maxContentSize
+	})
+	return count, err
+}
+
+// splitMbox 逐封读出 mbox 中的邮件：位于文件开头或空行之后、以「From 」开头的行是分隔行，
+// 正文中转义为「>From 」（含多个 >）的行去掉一个 >；单封邮件超过 emlMaxPartSize 的部分丢弃。
+// fn 收到的切片在下次调用前有效，返回 false 时停止
+func splitMbox(r io.Reader, fn func(msg []byte) bool) error {
+	br := bufio.NewReader(r)
+	var msg bytes.Buffer
+	started, blank := false, true
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if blank && bytes.HasPrefix(line, []byte("From ")) {
+				if started && !fn(msg.Bytes()) {
+					return nil
+				}
+				msg.Reset()
+				started, blank = true, false
+			} else {
+				if started && msg.Len() < emlMaxPartSize {
+					if quoted := bytes.TrimLeft(line, ">"); len(quoted) < len(line) && bytes.HasPrefix(quoted, []byte("From ")) {
+						line = line[1:]
+					}
+					msg.Write(line)
+				}
+				blank = len(bytes.TrimRight(line, "\r\n")) == 0
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if started {
+		fn(msg.Bytes())
+	}
+	return nil
+}
+
+func init() {
+	Register(".mbox", &mboxExtractor{})
+}
@@ -0,0 +1,74 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMBOXExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		mbox    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "多封邮件，正文中的 >From 去掉一个 >",
+			mbox: strings.Join([]string{
+				"From alice@example.com Mon Jan  1 00:00:00 2024",
+				"Subject: =?UTF-8?B?" + b64("第一封") + "?=",
+				"",
+				">From the start",
+				">>From quoted",
+				"From 不在空行之后，属于正文",
+				"",
+				"From bob@example.com Tue Jan  2 00:00:00 2024",
+				"Subject: Second",
+				"",
+				"第二封正文",
+				"",
+			}, "\n"),
+			want: "邮件 1\n主题: 第一封\n\nFrom the start\n>From quoted\nFrom 不在空行之后，属于正文\n\n" +
+				"邮件 2\n主题: Second\n\n第二封正文",
+		},
+		{
+			name: "附件中的邮箱",
+			mbox: strings.Join([]string{
+				"From a@example.com Mon Jan  1 00:00:00 2024",
+				"Subject: Archive",
+				`Content-Type: multipart/mixed; boundary="b"`,
+				"",
+				"--b",
+				`Content-Type: application/mbox; name="old.mbox"`,
+				"Content-Transfer-Encoding: base64",
+				"",
+				b64("From c@example.com Mon Jan  1 00:00:00 2023\nSubject: Old\n\n旧邮件\n"),
+				"--b--",
+				"",
+			}, "\n"),
+			want: "邮件 1\n主题: Archive\n\n\n附件: old.mbox\n邮件 1\n主题: Old\n\n旧邮件",
+		},
+		{
+			name:    "没有分隔行",
+			mbox:    "Subject: x\n\nbody\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&mboxExtractor{}).Extract(writeTemp(t, "a.mbox", []byte(tt.mbox)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
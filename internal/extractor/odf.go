@@ -0,0 +1,230 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// odfExtractor 读取 OpenDocument 文档（odt/ods/odp）content.xml 中的正文：
+// 段落和标题各占一行，表格每行一行、非空单元格以制表符分隔；
+// 电子表格在每个工作表前输出「工作表: 名称」，演示文稿在每页前输出「幻灯片 N」，演讲者备注以「备注: 」开头
+// 修订记录中已删除的文本不输出
+type odfExtractor struct{}
+
+func (o *odfExtractor) Extract(path string) (string, error) {
+	zf, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("打开文档失败: %v", err)
+	}
+	defer zf.Close()
+
+	var content *zip.File
+	for _, f := range zf.File {
+		if f.Name == "content.xml" {
+			content = f
+			break
+		}
+	}
+	if content == nil {
+		return "", fmt.Errorf("未找到 content.xml")
+	}
+	rc, err := content.Open()
+	if err != nil {
+		return "", fmt.Errorf("读取 content.xml 失败: %v", err)
+	}
+	defer rc.Close()
+
+	text, err := odfText(rc)
+	if err != nil {
+		return "", fmt.Errorf("解析 content.xml 失败: %v", err)
+	}
+	return limitContent(strings.TrimSpace(text)), nil
+}
+
+// Metadata 读取 meta.xml 中的标题、作者等属性
+func (o *odfExtractor) Metadata(path string) (map[string]string, error) {
+	zf, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文档失败: %v", err)
+	}
+	defer zf.Close()
+
+	result := make(map[string]string)
+	for _, f := range zf.File {
+		if f.Name != "meta.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取 meta.xml 失败: %v", err)
+		}
+		defer rc.Close()
+
+		// office:meta 下为扁平结构，直接按元素本地名收集文本；关键词可能有多个
+		decoder := xml.NewDecoder(rc)
+		var current string
+		var keywords []string
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				current = strings.ToLower(t.Name.Local)
+			case xml.CharData:
+				text := strings.TrimSpace(string(t))
+				if text == "" || current == "" {
+					continue
+				}
+				if current == "keyword" {
+					keywords = append(keywords, text)
+				} else {
+					result[current] = text
+				}
+			case xml.EndElement:
+				current = ""
+			}
+		}
+		if len(keywords) > 0 {
+			result["keywords"] = strings.Join(keywords, ", ")
+		}
+		// 统一作者字段名：优先使用创建者
+		if creator, ok := result["initial-creator"]; ok {
+			result["author"] = creator
+		} else if creator, ok := result["creator"]; ok {
+			result["author"] = creator
+		}
+		break
+	}
+	return result, nil
+}
+
+// odfText 流式解析 content.xml，内容达到上限后停止
+func odfText(r io.Reader) (string, error) {
+	var (
+		b       strings.Builder
+		line    strings.Builder // 当前段落
+		cell    strings.Builder // 当前单元格，单元格内的多个段落以空格连接
+		notes   strings.Builder // 当前演讲者备注
+		cells   []string        // 当前表格行中的非空单元格
+		inCell  bool
+		inNotes bool
+		skip    int // 位于不输出的元素中（嵌套深度）
+		slides  int
+	)
+	// endParagraph 段落结束时写入所在的单元格、备注或正文
+	endParagraph := func() {
+		text := strings.TrimSpace(line.String())
+		line.Reset()
+		switch {
+		case text == "":
+		case inNotes:
+			notes.WriteString(text)
+			notes.WriteByte(' ')
+		case inCell:
+			cell.WriteString(text)
+			cell.WriteByte(' ')
+		default:
+			b.WriteString(text)
+			b.WriteByte('\n')
+		}
+	}
+
+	decoder := xml.NewDecoder(r)
+	for b.Len() < maxContentSize {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			switch t.Name.Local {
+			case "tracked-changes", "annotation":
+				skip = 1
+			case "table":
+				if name := xmlAttr(t, "name"); name != "" && t.Name.Space == odfTableNS && !inCell {
+					fmt.Fprintf(&b, "工作表: %s\n", name)
+				}
+			case "page":
+				if t.Name.Space == odfDrawNS {
+					slides++
+					fmt.Fprintf(&b, "幻灯片 %d\n", slides)
+				}
+			case "notes":
+				inNotes = true
+				notes.Reset()
+			case "table-row":
+				cells = cells[:0]
+			case "table-cell":
+				inCell = true
+				cell.Reset()
+			case "s":
+				n, err := strconv.Atoi(xmlAttr(t, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				line.WriteString(strings.Repeat(" ", min(n, 16)))
+			case "tab":
+				line.WriteByte('\t')
+			case "line-break":
+				line.WriteByte(' ')
+			}
+		case xml.CharData:
+			if skip == 0 {
+				line.Write(t)
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			switch t.Name.Local {
+			case "p", "h":
+				endParagraph()
+			case "notes":
+				inNotes = false
+				if text := strings.TrimSpace(notes.String()); text != "" {
+					b.WriteString("备注: ")
+					b.WriteString(text)
+					b.WriteByte('\n')
+				}
+			case "table-cell":
+				inCell = false
+				if text := strings.TrimSpace(cell.String()); text != "" {
+					cells = append(cells, text)
+				}
+			case "table-row":
+				if len(cells) > 0 {
+					b.WriteString(strings.Join(cells, "\t"))
+					b.WriteByte('\n')
+				}
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// OpenDocument 命名空间
+const (
+	odfTableNS = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odfDrawNS  = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+)
+
+func init() {
+	o := &odfExtractor{}
+	for _, ext := range []string{".odt", ".ods", ".odp"} {
+		Register(ext, o)
+	}
+}
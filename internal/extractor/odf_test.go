@@ -0,0 +1,77 @@
+package extractor
+
+import "testing"
+
+// odfContent 生成 content.xml，body 为 office:body 中的内容
+func odfContent(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" ` +
+		`xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" ` +
+		`xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" ` +
+		`xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" ` +
+		`xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/">
+<office:body>` + body + `</office:body></office:document-content>`
+}
+
+func TestODFExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "文本文档跳过修订和批注",
+			data: buildZip(zipFile{"content.xml", odfContent(`<office:text>
+<text:tracked-changes><text:changed-region><text:deletion><text:p>已删除</text:p></text:deletion></text:changed-region></text:tracked-changes>
+<text:h text:outline-level="1">季度报告</text:h>
+<text:p>A<text:s text:c="3"/>B<text:tab/>C<office:annotation><dc:creator>张三</dc:creator><text:p>批注</text:p></office:annotation></text:p>
+<text:p>第一行<text:line-break/>第二行</text:p>
+<table:table><table:table-row><table:table-cell><text:p>名称</text:p></table:table-cell><table:table-cell><text:p>数量</text:p></table:table-cell></table:table-row></table:table>
+</office:text>`)}),
+			want: "季度报告\nA   B\tC\n第一行 第二行\n名称\t数量",
+		},
+		{
+			name: "电子表格输出工作表名，空单元格不输出",
+			data: buildZip(zipFile{"content.xml", odfContent(`<office:spreadsheet>
+<table:table table:name="汇总"><table:table-row><table:table-cell><text:p>名称</text:p></table:table-cell><table:table-cell/><table:table-cell><text:p>金额</text:p><text:p>（元）</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell table:number-columns-repeated="3"/></table:table-row></table:table>
+<table:table table:name="明细"><table:table-row><table:table-cell><text:p>1234.5</text:p></table:table-cell></table:table-row></table:table>
+</office:spreadsheet>`)}),
+			want: "工作表: 汇总\n名称\t金额 （元）\n工作表: 明细\n1234.5",
+		},
+		{
+			name: "演示文稿输出幻灯片序号和备注",
+			data: buildZip(zipFile{"content.xml", odfContent(`<office:presentation>
+<draw:page draw:name="page1"><draw:frame><draw:text-box><text:p>目录</text:p></draw:text-box></draw:frame>
+<presentation:notes><draw:frame><draw:text-box><text:p>开场</text:p><text:p>介绍</text:p></draw:text-box></draw:frame></presentation:notes></draw:page>
+<draw:page draw:name="page2"><draw:frame><draw:text-box><text:p>结论</text:p></draw:text-box></draw:frame></draw:page>
+</office:presentation>`)}),
+			want: "幻灯片 1\n目录\n备注: 开场 介绍\n幻灯片 2\n结论",
+		},
+		{
+			name:    "缺少 content.xml",
+			data:    buildZip(zipFile{"meta.xml", "<office:document-meta/>"}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.odt", tt.data)
+			got, err := (&odfExtractor{}).Extract(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ooxmlRelationship 部件之间的关系，Target 已换算为 zip 中的完整路径
+type ooxmlRelationship struct {
+	Type   string
+	Target string
+}
+
+// ooxmlRelationships 读取部件（如 xl/workbook.xml）对应的 _rels 文件，按关系 Id 返回目标部件；
+// 关系文件不存在时返回空表
+func ooxmlRelationships(files map[string]*zip.File, part string) (map[string]ooxmlRelationship, error) {
+	dir, name := path.Split(part)
+	result := make(map[string]ooxmlRelationship)
+	f := files[dir+"_rels/"+name+".rels"]
+	if f == nil {
+		return result, nil
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readZipXML(f, &rels); err != nil {
+		return nil, fmt.Errorf("读取 %s 的关系失败: %v", part, err)
+	}
+	for _, r := range rels.Relationships {
+		target := r.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(dir, target)
+		}
+		result[r.ID] = ooxmlRelationship{Type: r.Type, Target: target}
+	}
+	return result, nil
+}
+
+// readZipXML 解析 zip 中的 XML 文件
+func readZipXML(f *zip.File, v interface{}) error {
+	if f == nil {
+		return fmt.Errorf("文件不存在")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xmlAttr 返回元素的属性值（按本地名匹配）
+func xmlAttr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// ooxmlCoreProperties 读取 OOXML 文档（docx/xlsx/pptx）docProps/core.xml 中的核心属性
+func ooxmlCoreProperties(path string) (map[string]string, error) {
+	zf, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文档失败: %v", err)
+	}
+	defer zf.Close()
+
+	result := make(map[string]string)
+	for _, f := range zf.File {
+		if f.Name != "docProps/core.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取 core.xml 失败: %v", err)
+		}
+		defer rc.Close()
+
+		// core.xml 为扁平结构，直接按元素本地名收集文本
+		decoder := xml.NewDecoder(rc)
+		var current string
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				current = strings.ToLower(t.Name.Local)
+			case xml.CharData:
+				if text := strings.TrimSpace(string(t)); text != "" && current != "" {
+					result[current] = text
+				}
+			case xml.EndElement:
+				current = ""
+			}
+		}
+		// 统一作者字段名
+		if creator, ok := result["creator"]; ok {
+			result["author"] = creator
+		}
+		break
+	}
+	return result, nil
+}
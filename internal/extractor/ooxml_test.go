@@ -0,0 +1,62 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// zipFile zip 中的一个文件
+type zipFile struct {
+	name string
+	body string
+}
+
+// buildZip 按顺序把各文件写入 zip
+func buildZip(files ...zipFile) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			panic(err)
+		}
+		w.Write([]byte(f.body))
+	}
+	if err := zw.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOOXMLRelationships(t *testing.T) {
+	data := buildZip(zipFile{"ppt/slides/_rels/slide1.xml.rels", `<?xml version="1.0"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="/ppt/media/image1.png"/>
+</Relationships>`})
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	rels, err := ooxmlRelationships(files, "ppt/slides/slide1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rels["rId1"].Target; got != "ppt/notesSlides/notesSlide1.xml" {
+		t.Errorf("相对路径 = %q", got)
+	}
+	if got := rels["rId2"].Target; got != "ppt/media/image1.png" {
+		t.Errorf("绝对路径 = %q", got)
+	}
+
+	rels, err = ooxmlRelationships(files, "ppt/slides/slide2.xml")
+	if err != nil || len(rels) != 0 {
+		t.Errorf("缺少关系文件时应返回空表: %v, %v", rels, err)
+	}
+}
@@ -37,6 +37,24 @@ func (p *pdfExtractor) Extract(path string) (string, error) {
 	return content, nil
 }
 
+// Metadata 读取 PDF 文档信息字典（Info）
+func (p *pdfExtractor) Metadata(path string) (map[string]string, error) {
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 PDF 失败: %v", err)
+	}
+	defer f.Close()
+
+	result := make(map[string]string)
+	info := r.Trailer().Key("Info")
+	for _, key := range info.Keys() {
+		if text := strings.TrimSpace(info.Key(key).Text()); text != "" {
+			result[strings.ToLower(key)] = text
+		}
+	}
+	return result, nil
+}
+
 func init() {
 	Register(".pdf", &pdfExtractor{})
 }
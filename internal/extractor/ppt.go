@@ -0,0 +1,350 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// PowerPoint 97-2003 演示文稿："PowerPoint Document" 流由嵌套的记录组成（8 字节记录头，
+// 版本号为 0xF 的是容器），文本保存在 TextCharsAtom（UTF-16LE）和 TextBytesAtom（单字节）中。
+// 文档容器的 SlideListWithText 按放映顺序列出各页的 SlidePersistAtom（持久化 ID）及占位符文本；
+// 其他文本框的文本位于各页的 Slide 容器中，持久化目录（PersistDirectoryAtom）给出持久化 ID 对应的容器位置。
+// 备注页同样有自己的列表和 Notes 容器，SlideAtom 的 notesIdRef 指向本页备注的持久化 ID
+
+// PowerPoint 记录类型
+const (
+	pptDocument          = 0x03E8
+	pptSlide             = 0x03EE
+	pptSlideAtom         = 0x03EF
+	pptNotes             = 0x03F0
+	pptNotesAtom         = 0x03F1
+	pptSlidePersistAtom  = 0x03F3
+	pptMainMaster        = 0x03F8
+	pptHandout           = 0x0FC9
+	pptSlideListWithText = 0x0FF0
+	pptUserEditAtom      = 0x0FF5
+	pptTextCharsAtom     = 0x0FA0
+	pptTextBytesAtom     = 0x0FA8
+	pptPersistDirectory  = 0x1772
+	pptMaxDepth          = 32
+)
+
+// SlideListWithText 的实例号
+const (
+	pptListSlides = 0
+	pptListNotes  = 2
+)
+
+// pptExtractor 按放映顺序提取 .ppt 每页幻灯片的文本和演讲者备注，输出格式与 pptx 相同：
+// 每页以「幻灯片 N」开头，备注以「备注: 」开头，同一页中重复的文本只输出一次；母版和讲义中的文本不输出。
+// 找不到幻灯片列表或持久化目录时，尽力按记录顺序输出全部文本
+type pptExtractor struct{}
+
+// pptRecord 一条记录
+type pptRecord struct {
+	typ      uint16
+	instance uint16
+	version  uint16
+	body     []byte
+}
+
+// pptListEntry SlideListWithText 中的一页：持久化 ID、幻灯片 ID 和占位符文本
+type pptListEntry struct {
+	persist uint32
+	slideID uint32
+	texts   []string
+}
+
+// pptBlock 一页幻灯片或备注的文本，同一块中重复的文本只保留一次
+type pptBlock struct {
+	lines []string
+	seen  map[string]bool
+}
+
+func (p *pptExtractor) Extract(path string) (string, error) {
+	cf, f, err := openCFB(path)
+	if err != nil {
+		return "", fmt.Errorf("打开 ppt 失败: %v", err)
+	}
+	defer f.Close()
+
+	stream, err := cf.Stream("PowerPoint Document")
+	if err != nil {
+		return "", err
+	}
+	if text, ok := pptSlidesText(stream); ok {
+		return limitContent(strings.TrimSpace(text)), nil
+	}
+	w := &pptWalker{seen: make(map[string]bool)}
+	w.walk(stream, 0, "")
+	return limitContent(strings.TrimSpace(w.b.String())), nil
+}
+
+// Metadata 读取摘要信息中的标题、作者等属性
+func (p *pptExtractor) Metadata(path string) (map[string]string, error) {
+	return cfbMetadata(path)
+}
+
+// pptSlidesText 按幻灯片列表逐页输出文本；缺少文档容器、幻灯片列表或持久化目录时返回 false
+func pptSlidesText(stream []byte) (string, bool) {
+	top := pptRecords(stream)
+
+	// 增量保存会在流末尾追加新的持久化目录，后出现的覆盖先出现的；旧版本的容器不再被引用
+	persist := make(map[uint32]int)
+	docRef := uint32(1)
+	for _, r := range top {
+		switch r.typ {
+		case pptPersistDirectory:
+			pptReadPersistDirectory(r.body, persist)
+		case pptUserEditAtom:
+			if len(r.body) >= 20 {
+				docRef = binary.LittleEndian.Uint32(r.body[16:])
+			}
+		}
+	}
+	if len(persist) == 0 {
+		return "", false
+	}
+	doc, ok := pptRecordAt(stream, persist[docRef])
+	if !ok || doc.typ != pptDocument {
+		return "", false
+	}
+
+	var slides []pptListEntry
+	notes := make(map[uint32]pptListEntry)
+	found := false
+	for _, r := range pptRecords(doc.body) {
+		if r.typ != pptSlideListWithText {
+			continue
+		}
+		switch r.instance {
+		case pptListSlides:
+			slides, found = pptListEntries(r.body), true
+		case pptListNotes:
+			for _, e := range pptListEntries(r.body) {
+				notes[e.persist] = e
+			}
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	// 备注页的 NotesAtom 记录所属幻灯片的 ID，作为 notesIdRef 缺失时的后备
+	notesBySlide := make(map[uint32]uint32)
+	for id, off := range persist {
+		if r, ok := pptRecordAt(stream, off); ok && r.typ == pptNotes {
+			if atom, ok := pptChild(r.body, pptNotesAtom); ok && len(atom) >= 4 {
+				notesBySlide[binary.LittleEndian.Uint32(atom)] = id
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, entry := range slides {
+		if b.Len() >= maxContentSize {
+			break
+		}
+		slide := newPPTBlock()
+		for _, s := range entry.texts {
+			slide.add(s)
+		}
+		notesRef, hasNotes := notesBySlide[entry.slideID]
+		if r, ok := pptRecordAt(stream, persist[entry.persist]); ok && r.typ == pptSlide {
+			pptCollect(r.body, 0, slide.add)
+			if atom, ok := pptChild(r.body, pptSlideAtom); ok && len(atom) >= 20 {
+				if ref := binary.LittleEndian.Uint32(atom[16:]); ref != 0 {
+					notesRef, hasNotes = ref, true
+				}
+			}
+		}
+
+		note := newPPTBlock()
+		if hasNotes {
+			for _, s := range notes[notesRef].texts {
+				note.add(s)
+			}
+			if r, ok := pptRecordAt(stream, persist[notesRef]); ok && r.typ == pptNotes {
+				pptCollect(r.body, 0, note.add)
+			}
+		}
+
+		if len(slide.lines) == 0 && len(note.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "幻灯片 %d\n", i+1)
+		for _, line := range slide.lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+		if len(note.lines) > 0 {
+			b.WriteString("备注: ")
+			b.WriteString(strings.Join(note.lines, "\n"))
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), true
+}
+
+// pptRecords 解析一段数据中的同级记录；长度超出数据的记录截断到末尾
+func pptRecords(data []byte) []pptRecord {
+	var result []pptRecord
+	for pos := 0; pos+8 <= len(data); {
+		r, _ := pptRecordAt(data, pos)
+		result = append(result, r)
+		pos += 8 + len(r.body)
+	}
+	return result
+}
+
+// pptRecordAt 读取 offset 处的记录
+func pptRecordAt(data []byte, offset int) (pptRecord, bool) {
+	if offset < 0 || offset+8 > len(data) {
+		return pptRecord{}, false
+	}
+	le := binary.LittleEndian
+	verInst := le.Uint16(data[offset:])
+	body := data[offset+8:]
+	if size := le.Uint32(data[offset+4:]); uint64(size) < uint64(len(body)) {
+		body = body[:size]
+	}
+	return pptRecord{
+		typ:      le.Uint16(data[offset+2:]),
+		instance: verInst >> 4,
+		version:  verInst & 0x0F,
+		body:     body,
+	}, true
+}
+
+// pptChild 返回容器中第一个指定类型的子记录的内容
+func pptChild(data []byte, typ uint16) ([]byte, bool) {
+	for _, r := range pptRecords(data) {
+		if r.typ == typ {
+			return r.body, true
+		}
+	}
+	return nil, false
+}
+
+// pptReadPersistDirectory 读取持久化目录：每项为起始 ID（低 20 位）和个数（高 12 位），后跟各 ID 的位置
+func pptReadPersistDirectory(body []byte, persist map[uint32]int) {
+	le := binary.LittleEndian
+	for pos := 0; pos+4 <= len(body); {
+		head := le.Uint32(body[pos:])
+		id, count := head&0xFFFFF, int(head>>20)
+		pos += 4
+		for i := 0; i < count && pos+4 <= len(body); i++ {
+			persist[id+uint32(i)] = int(le.Uint32(body[pos:]))
+			pos += 4
+		}
+	}
+}
+
+// pptListEntries 读取 SlideListWithText：每个 SlidePersistAtom 开始新的一页，其后的文本属于该页
+func pptListEntries(body []byte) []pptListEntry {
+	var entries []pptListEntry
+	for _, r := range pptRecords(body) {
+		switch r.typ {
+		case pptSlidePersistAtom:
+			if len(r.body) < 16 {
+				continue
+			}
+			entries = append(entries, pptListEntry{
+				persist: binary.LittleEndian.Uint32(r.body),
+				slideID: binary.LittleEndian.Uint32(r.body[12:]),
+			})
+		case pptTextCharsAtom, pptTextBytesAtom:
+			if len(entries) > 0 {
+				last := &entries[len(entries)-1]
+				last.texts = append(last.texts, pptAtomText(r))
+			}
+		}
+	}
+	return entries
+}
+
+// pptCollect 收集容器（含嵌套的绘图数据）中的全部文本
+func pptCollect(data []byte, depth int, add func(string)) {
+	if depth > pptMaxDepth {
+		return
+	}
+	for _, r := range pptRecords(data) {
+		switch {
+		case r.typ == pptTextCharsAtom || r.typ == pptTextBytesAtom:
+			add(pptAtomText(r))
+		case r.version == 0x0F:
+			pptCollect(r.body, depth+1, add)
+		}
+	}
+}
+
+// pptAtomText 解码文本记录，段落分隔符转为换行
+func pptAtomText(r pptRecord) string {
+	s := latin1(r.body)
+	if r.typ == pptTextCharsAtom {
+		s = utf16LE(r.body)
+	}
+	return strings.NewReplacer("\r", "\n", "\v", "\n").Replace(s)
+}
+
+func newPPTBlock() *pptBlock {
+	return &pptBlock{seen: make(map[string]bool)}
+}
+
+// add 添加一段文本；「*」是页码等域的占位文本，不输出
+func (b *pptBlock) add(s string) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" || b.seen[s] {
+		return
+	}
+	b.seen[s] = true
+	b.lines = append(b.lines, s)
+}
+
+// pptWalker 按记录顺序遍历时的状态，用于无法按幻灯片组织的文件
+type pptWalker struct {
+	b    strings.Builder
+	seen map[string]bool
+}
+
+// walk 遍历一段记录，prefix 为其中文本的前缀（备注为「备注: 」）
+func (w *pptWalker) walk(data []byte, depth int, prefix string) {
+	if depth > pptMaxDepth {
+		return
+	}
+	for _, r := range pptRecords(data) {
+		if w.b.Len() >= maxContentSize {
+			return
+		}
+		switch {
+		case r.typ == pptMainMaster || r.typ == pptHandout:
+			// 母版和讲义中只有占位提示文本
+		case r.typ == pptSlideListWithText && r.instance != pptListSlides && r.instance != pptListNotes:
+			// 母版的文本列表
+		case r.typ == pptSlideListWithText && r.instance == pptListNotes, r.typ == pptNotes:
+			w.walk(r.body, depth+1, "备注: ")
+		case r.typ == pptTextCharsAtom || r.typ == pptTextBytesAtom:
+			w.text(pptAtomText(r), prefix)
+		case r.version == 0x0F:
+			w.walk(r.body, depth+1, prefix)
+		}
+	}
+}
+
+// text 写出一段文本，重复出现的文本只输出一次
+func (w *pptWalker) text(s, prefix string) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "*" || w.seen[s] {
+		return
+	}
+	w.seen[s] = true
+	w.b.WriteString(prefix)
+	w.b.WriteString(s)
+	w.b.WriteByte('\n')
+}
+
+func init() {
+	Register(".ppt", &pptExtractor{})
+}
@@ -0,0 +1,159 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// pptAtom 生成一条原子记录（版本号 0）
+func pptAtom(typ, instance uint16, body []byte) []byte {
+	rec := make([]byte, 8, 8+len(body))
+	binary.LittleEndian.PutUint16(rec, instance<<4)
+	binary.LittleEndian.PutUint16(rec[2:], typ)
+	binary.LittleEndian.PutUint32(rec[4:], uint32(len(body)))
+	return append(rec, body...)
+}
+
+// pptContainer 生成一条容器记录（版本号 0xF）
+func pptContainer(typ, instance uint16, children ...[]byte) []byte {
+	var body []byte
+	for _, c := range children {
+		body = append(body, c...)
+	}
+	rec := pptAtom(typ, instance, body)
+	binary.LittleEndian.PutUint16(rec, instance<<4|0x0F)
+	return rec
+}
+
+func pptChars(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	body := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(body[2*i:], u)
+	}
+	return pptAtom(pptTextCharsAtom, 0, body)
+}
+
+func pptBytes(s string) []byte {
+	return pptAtom(pptTextBytesAtom, 0, []byte(s))
+}
+
+func pptU32(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+// pptPersist 生成 SlidePersistAtom：持久化 ID、标志、文本数、幻灯片 ID、保留
+func pptPersist(persist, slideID uint32) []byte {
+	return pptAtom(pptSlidePersistAtom, 0, pptU32(persist, 0, 0, slideID, 0))
+}
+
+// pptSlideContainer 生成 Slide 容器，notes 为本页备注的持久化 ID（0 表示无）
+func pptSlideContainer(notes uint32, texts ...[]byte) []byte {
+	atom := pptAtom(pptSlideAtom, 0, pptU32(0, 0, 0, 0x80000000, notes, 0))
+	drawing := pptContainer(0x040C, 0, pptContainer(0xF002, 0, pptContainer(0xF00D, 0, texts...)))
+	return pptContainer(pptSlide, 0, atom, drawing)
+}
+
+// pptNotesContainer 生成 Notes 容器，slideID 为所属幻灯片的 ID
+func pptNotesContainer(slideID uint32, texts ...[]byte) []byte {
+	atom := pptAtom(pptNotesAtom, 0, pptU32(slideID, 0))
+	return pptContainer(pptNotes, 0, atom, pptContainer(0x040C, 0, texts...))
+}
+
+// pptBuilder 按顺序写出顶层记录，记录持久化 ID 对应的位置
+type pptBuilder struct {
+	stream  []byte
+	offsets map[uint32]uint32
+}
+
+func (b *pptBuilder) add(persist uint32, rec []byte) {
+	if persist != 0 {
+		b.offsets[persist] = uint32(len(b.stream))
+	}
+	b.stream = append(b.stream, rec...)
+}
+
+// finish 写出持久化目录（ID 1 起连续）和指向文档（ID 1）的 UserEditAtom
+func (b *pptBuilder) finish() []byte {
+	n := uint32(len(b.offsets))
+	dir := pptU32(1 | n<<20)
+	for id := uint32(1); id <= n; id++ {
+		dir = append(dir, pptU32(b.offsets[id])...)
+	}
+	b.add(0, pptAtom(pptPersistDirectory, 0, dir))
+	b.add(0, pptAtom(pptUserEditAtom, 0, pptU32(0, 0, 0, 0, 1, 0, 0)))
+	return b.stream
+}
+
+// samplePPT 两页演示文稿：幻灯片容器在流中逆序存放，另有一个不再被引用的旧版本幻灯片
+func samplePPT() []byte {
+	b := &pptBuilder{offsets: make(map[uint32]uint32)}
+	doc := pptContainer(pptDocument, 0,
+		pptContainer(pptSlideListWithText, 1, pptPersist(2, 0), pptChars("母版标题")),
+		pptContainer(pptSlideListWithText, pptListSlides,
+			pptPersist(3, 256), pptChars("年度报告\r第一季度"),
+			pptPersist(4, 257), pptChars("财务概览"),
+		),
+		pptContainer(pptSlideListWithText, pptListNotes,
+			pptPersist(5, 0), pptChars("第一页备注"),
+		),
+	)
+	b.add(1, doc)
+	b.add(2, pptContainer(pptMainMaster, 0, pptContainer(0x040C, 0, pptChars("单击此处编辑母版标题样式"))))
+	b.add(0, pptSlideContainer(0, pptChars("旧版本的文本")))
+	b.add(4, pptSlideContainer(0, pptBytes("Text box B"), pptChars("年度报告"), pptChars("*")))
+	b.add(3, pptSlideContainer(5, pptBytes("Text box A"), pptChars("年度报告"), pptChars("年度报告")))
+	b.add(5, pptNotesContainer(256, pptChars("第一页备注"), pptChars("补充说明")))
+	b.add(6, pptNotesContainer(257, pptChars("第二页备注")))
+	return b.finish()
+}
+
+func TestPPTExtract(t *testing.T) {
+	// 没有持久化目录时按记录顺序输出，母版文本仍不输出
+	legacy := pptContainer(pptDocument, 0,
+		pptContainer(pptSlideListWithText, pptListSlides, pptPersist(3, 256), pptChars("标题")),
+		pptContainer(pptSlideListWithText, pptListNotes, pptPersist(5, 0), pptChars("备注文本")),
+	)
+	legacy = append(legacy, pptContainer(pptMainMaster, 0, pptChars("母版"))...)
+	legacy = append(legacy, pptSlideContainer(0, pptChars("文本框"), pptChars("标题"))...)
+
+	tests := []struct {
+		name   string
+		stream []byte
+		want   string
+	}{
+		{
+			name:   "按放映顺序分页",
+			stream: samplePPT(),
+			want: "幻灯片 1\n年度报告\n第一季度\nText box A\n年度报告\n备注: 第一页备注\n补充说明\n\n" +
+				"幻灯片 2\n财务概览\nText box B\n年度报告\n备注: 第二页备注",
+		},
+		{
+			name:   "缺少持久化目录",
+			stream: legacy,
+			want:   "标题\n备注: 备注文本\n文本框",
+		},
+		{
+			name:   "空流",
+			stream: nil,
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.ppt", buildCFB(cfbStream{"PowerPoint Document", tt.stream}))
+			got, err := (&pptExtractor{}).Extract(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 关系类型后缀
+const (
+	pptxSlideRel = "/slide"
+	pptxNotesRel = "/notesSlide"
+)
+
+var pptxSlideName = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+
+// pptxExtractor 按放映顺序提取每页幻灯片的文本和演讲者备注，每页输出为一个文本块：
+//
+//	幻灯片 1
+//	标题和正文……
+//	备注: 演讲者备注……
+type pptxExtractor struct{}
+
+func (p *pptxExtractor) Extract(path string) (string, error) {
+	zf, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("打开 pptx 失败: %v", err)
+	}
+	defer zf.Close()
+
+	files := make(map[string]*zip.File, len(zf.File))
+	for _, f := range zf.File {
+		files[f.Name] = f
+	}
+	slides, err := pptxSlides(files)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, slide := range slides {
+		if b.Len() >= maxContentSize {
+			break
+		}
+		text, err := pptxText(files[slide], false)
+		if err != nil {
+			return "", fmt.Errorf("读取第 %d 页幻灯片失败: %v", i+1, err)
+		}
+		notes := ""
+		if rels, err := ooxmlRelationships(files, slide); err == nil {
+			for _, r := range rels {
+				if strings.HasSuffix(r.Type, pptxNotesRel) {
+					// 备注读取失败不影响正文
+					notes, _ = pptxText(files[r.Target], true)
+					break
+				}
+			}
+		}
+		if text == "" && notes == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "幻灯片 %d\n", i+1)
+		if text != "" {
+			b.WriteString(text)
+			b.WriteByte('\n')
+		}
+		if notes != "" {
+			b.WriteString("备注: ")
+			b.WriteString(notes)
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	return limitContent(strings.TrimSpace(b.String())), nil
+}
+
+// Metadata 读取 docProps/core.xml 中的标题、作者等属性
+func (p *pptxExtractor) Metadata(path string) (map[string]string, error) {
+	return ooxmlCoreProperties(path)
+}
+
+// pptxSlides 按 presentation.xml 中的放映顺序列出幻灯片；
+// 无法读取时按文件名中的序号排序
+func pptxSlides(files map[string]*zip.File) ([]string, error) {
+	var presentation struct {
+		Slides []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if f := files["ppt/presentation.xml"]; f != nil {
+		if err := readZipXML(f, &presentation); err != nil {
+			return nil, fmt.Errorf("读取 presentation.xml 失败: %v", err)
+		}
+		rels, err := ooxmlRelationships(files, "ppt/presentation.xml")
+		if err != nil {
+			return nil, err
+		}
+		var slides []string
+		for _, s := range presentation.Slides {
+			if r, ok := rels[s.ID]; ok && strings.HasSuffix(r.Type, pptxSlideRel) && files[r.Target] != nil {
+				slides = append(slides, r.Target)
+			}
+		}
+		if len(slides) > 0 {
+			return slides, nil
+		}
+	}
+
+	type numbered struct {
+		name string
+		n    int
+	}
+	var found []numbered
+	for name := range files {
+		if m := pptxSlideName.FindStringSubmatch(name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			found = append(found, numbered{name, n})
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("未找到幻灯片")
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].n < found[j].n })
+	slides := make([]string, len(found))
+	for i, s := range found {
+		slides[i] = s.name
+	}
+	return slides, nil
+}
+
+// pptxText 提取幻灯片或备注页中的文本，每个段落一行
+// notes 为 true 时只保留备注正文，跳过幻灯片缩略图、页码、页眉页脚等占位符
+func pptxText(f *zip.File, notes bool) (string, error) {
+	if f == nil {
+		return "", fmt.Errorf("文件不存在")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var (
+		lines     []string
+		paragraph strings.Builder
+		shape     []string // 当前形状中的段落
+		inShape   bool
+		skipShape bool
+		inText    bool
+	)
+	endParagraph := func() {
+		text := strings.TrimSpace(paragraph.String())
+		paragraph.Reset()
+		if text == "" {
+			return
+		}
+		if inShape {
+			shape = append(shape, text)
+		} else {
+			lines = append(lines, text)
+		}
+	}
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				inShape, skipShape, shape = true, false, shape[:0]
+			case "ph":
+				if notes && xmlAttr(t, "type") != "body" {
+					skipShape = true
+				}
+			case "t":
+				inText = true
+			case "br":
+				endParagraph()
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				endParagraph()
+			case "sp":
+				if !skipShape {
+					lines = append(lines, shape...)
+				}
+				inShape = false
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func init() {
+	Register(".pptx", &pptxExtractor{})
+}
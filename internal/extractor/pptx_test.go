@@ -0,0 +1,95 @@
+package extractor
+
+import "testing"
+
+// pptxSlide 生成含一个文本框的幻灯片，每个参数为一个段落
+func pptxSlide(paragraphs ...string) string {
+	body := ""
+	for _, p := range paragraphs {
+		body += "<a:p><a:r><a:t>" + p + "</a:t></a:r></a:p>"
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:spTree><p:sp><p:txBody>` + body + `</p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+}
+
+const (
+	// 放映顺序与文件名中的序号相反
+	pptxPresentation = `<?xml version="1.0" encoding="UTF-8"?>
+<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/></p:sldIdLst>
+</p:presentation>`
+	pptxPresentationRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideMaster" Target="slideMasters/slideMaster1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/>
+</Relationships>`
+	pptxSlideRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slideLayout" Target="../slideLayouts/slideLayout1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/>
+</Relationships>`
+	// 备注页中的缩略图和页码占位符不输出
+	pptxNotes = `<?xml version="1.0" encoding="UTF-8"?>
+<p:notes xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldImg"/></p:nvPr></p:nvSpPr></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>讲解</a:t></a:r><a:r><a:t>要点</a:t></a:r></a:p><a:p><a:r><a:t>第二行</a:t></a:r></a:p></p:txBody></p:sp>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum" idx="5"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>2</a:t></a:r></a:p></p:txBody></p:sp>
+</p:spTree></p:cSld></p:notes>`
+)
+
+func TestPPTXExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "按放映顺序输出幻灯片和备注",
+			data: buildZip(
+				zipFile{"ppt/presentation.xml", pptxPresentation},
+				zipFile{"ppt/_rels/presentation.xml.rels", pptxPresentationRels},
+				zipFile{"ppt/slides/slide1.xml", pptxSlide("季度报告", "第一季度")},
+				zipFile{"ppt/slides/_rels/slide1.xml.rels", pptxSlideRels},
+				zipFile{"ppt/notesSlides/notesSlide1.xml", pptxNotes},
+				zipFile{"ppt/slides/slide2.xml", pptxSlide("目录")},
+			),
+			want: "幻灯片 1\n目录\n\n幻灯片 2\n季度报告\n第一季度\n备注: 讲解要点\n第二行",
+		},
+		{
+			name: "缺少 presentation.xml 时按文件名中的序号排序",
+			data: buildZip(
+				zipFile{"ppt/slides/slide10.xml", pptxSlide("第十页")},
+				zipFile{"ppt/slides/slide2.xml", pptxSlide("第二页")},
+				zipFile{"ppt/slides/slide1.xml", pptxSlide("第一页")},
+			),
+			want: "幻灯片 1\n第一页\n\n幻灯片 2\n第二页\n\n幻灯片 3\n第十页",
+		},
+		{
+			name:    "没有幻灯片",
+			data:    buildZip(zipFile{"docProps/core.xml", "<cp:coreProperties/>"}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.pptx", tt.data)
+			got, err := (&pptxExtractor{}).Extract(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
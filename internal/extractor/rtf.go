@@ -0,0 +1,326 @@
+package extractor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// rtfExtractor 去掉 RTF 的控制字和字体表、样式表、图片等非正文内容，输出纯文本
+// \uN 按 Unicode 解码（含代理对），\'hh 按当前字体的字符集或文档代码页（\ansicpg）解码，
+// 中文文档通常为 GBK（代码页 936 / 字符集 134）
+type rtfExtractor struct{}
+
+// rtfSkipDestinations 不含正文的目标组，整组跳过
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"object": true, "fldinst": true, "listtable": true, "listoverridetable": true,
+	"revtbl": true, "rsidtbl": true, "generator": true, "datastore": true, "themedata": true,
+	"colorschememapping": true, "latentstyles": true, "xmlnstbl": true, "mmathPr": true,
+	"filetbl": true, "pgdsctbl": true, "header": true, "footer": true, "headerl": true,
+	"headerr": true, "headerf": true, "footerl": true, "footerr": true, "footerf": true,
+	"bkmkstart": true, "bkmkend": true, "nonshppict": true, "shppict": true,
+}
+
+// rtfCharsetCodepages 字体字符集（\fcharsetN）对应的代码页
+var rtfCharsetCodepages = map[int]int{
+	0:   1252,
+	128: 932,
+	129: 949,
+	134: 936,
+	136: 950,
+}
+
+// rtfGroup 组内的解析状态，进入 { 时复制，离开 } 时恢复
+type rtfGroup struct {
+	skip     bool // 位于跳过的目标组中
+	fonttbl  bool // 位于字体表中，只记录字体的字符集
+	uc       int  // \u 之后跳过的替代字符数
+	font     int
+	codepage int // 当前字体的代码页，0 表示使用文档代码页
+}
+
+// rtfParser RTF 解析器
+type rtfParser struct {
+	data      []byte
+	pos       int
+	out       strings.Builder
+	group     rtfGroup
+	stack     []rtfGroup
+	codepage  int         // 文档代码页
+	fonts     map[int]int // 字体号到代码页
+	pending   []byte      // 待解码的 \'hh 字节，多字节字符由连续的多个 \'hh 组成
+	surrogate uint16      // 待配对的高位代理
+	skipChars int         // \u 之后尚需跳过的替代字符数
+}
+
+func (r *rtfExtractor) Extract(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	if !strings.HasPrefix(string(data[:min(len(data), 5)]), `{\rtf`) {
+		return "", fmt.Errorf("不是有效的 RTF 文件")
+	}
+	p := &rtfParser{data: data, codepage: 1252, fonts: make(map[int]int), group: rtfGroup{uc: 1}}
+	p.parse()
+	return limitContent(strings.TrimSpace(p.out.String())), nil
+}
+
+func (p *rtfParser) parse() {
+	for p.pos < len(p.data) && p.out.Len() < maxContentSize {
+		c := p.data[p.pos]
+		switch c {
+		case '{':
+			p.flush()
+			p.stack = append(p.stack, p.group)
+			p.pos++
+		case '}':
+			p.flush()
+			if n := len(p.stack); n > 0 {
+				p.group = p.stack[n-1]
+				p.stack = p.stack[:n-1]
+			}
+			p.pos++
+		case '\\':
+			p.control()
+		case '\r', '\n':
+			p.pos++
+		default:
+			p.pos++
+			if p.skipChars > 0 {
+				p.skipChars--
+				continue
+			}
+			if c >= 0x80 {
+				// 个别编辑器直接写入未转义的多字节字符，与 \'hh 一样按代码页解码
+				p.pending = append(p.pending, c)
+				continue
+			}
+			p.flush()
+			if !p.group.skip && !p.group.fonttbl {
+				p.out.WriteByte(c)
+			}
+		}
+	}
+	p.flush()
+}
+
+// control 解析一个控制字或控制符
+func (p *rtfParser) control() {
+	p.pos++ // 跳过 '\'
+	if p.pos >= len(p.data) {
+		return
+	}
+	c := p.data[p.pos]
+
+	// 控制符
+	if !isASCIILetter(c) {
+		p.pos++
+		switch c {
+		case '\'':
+			if p.pos+2 <= len(p.data) {
+				if b, err := strconv.ParseUint(string(p.data[p.pos:p.pos+2]), 16, 8); err == nil {
+					p.pos += 2
+					if p.skipChars > 0 {
+						p.skipChars--
+						return
+					}
+					p.pending = append(p.pending, byte(b))
+					return
+				}
+			}
+		case '*':
+			// 可忽略的目标：本阅读器不认识的都跳过
+			p.group.skip = true
+		case '{', '}', '\\':
+			p.text(string(c))
+		case '~':
+			p.text(" ")
+		case '_':
+			p.text("-")
+		case '\r', '\n':
+			p.text("\n")
+		}
+		return
+	}
+
+	// 控制字：字母序列加可选的带符号数字参数，后跟的一个空格属于控制字
+	start := p.pos
+	for p.pos < len(p.data) && isASCIILetter(p.data[p.pos]) {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	numStart := p.pos
+	if p.pos < len(p.data) && p.data[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	param, hasParam := 0, p.pos > numStart
+	if hasParam {
+		param, _ = strconv.Atoi(string(p.data[numStart:p.pos]))
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == ' ' {
+		p.pos++
+	}
+	p.word(word, param, hasParam)
+}
+
+// word 处理控制字
+func (p *rtfParser) word(word string, param int, hasParam bool) {
+	if rtfSkipDestinations[word] {
+		if word == "fonttbl" {
+			p.group.fonttbl = true
+		} else {
+			p.group.skip = true
+		}
+		return
+	}
+	switch word {
+	case "bin":
+		// 二进制数据直接跳过
+		if hasParam && param > 0 {
+			p.pos = min(p.pos+param, len(p.data))
+		}
+	case "ansicpg":
+		if hasParam {
+			p.codepage = param
+		}
+	case "f":
+		if p.group.fonttbl {
+			p.group.font = param
+		} else {
+			p.group.font = param
+			p.group.codepage = p.fonts[param]
+		}
+	case "fcharset":
+		if p.group.fonttbl {
+			if cp, ok := rtfCharsetCodepages[param]; ok {
+				p.fonts[p.group.font] = cp
+			}
+		}
+	case "cpg":
+		if p.group.fonttbl && hasParam {
+			p.fonts[p.group.font] = param
+		}
+	case "uc":
+		if hasParam && param >= 0 {
+			p.group.uc = param
+		}
+	case "u":
+		p.flush()
+		unit := uint16(int16(param)) // 负数表示 32768 以上的码位
+		if !p.group.skip && !p.group.fonttbl {
+			p.unicode(unit)
+		}
+		p.skipChars = p.group.uc
+	case "par", "line", "sect", "page", "row":
+		p.text("\n")
+	case "tab", "cell":
+		p.text("\t")
+	case "emdash":
+		p.text("—")
+	case "endash":
+		p.text("–")
+	case "lquote":
+		p.text("‘")
+	case "rquote":
+		p.text("’")
+	case "ldblquote":
+		p.text("“")
+	case "rdblquote":
+		p.text("”")
+	case "bullet":
+		p.text("•")
+	}
+}
+
+// unicode 写出一个 UTF-16 码元，高位代理等待与下一个码元配对
+func (p *rtfParser) unicode(unit uint16) {
+	r := rune(unit)
+	switch {
+	case utf16.IsSurrogate(r) && p.surrogate == 0 && unit < 0xDC00:
+		p.surrogate = unit
+		return
+	case p.surrogate != 0:
+		r = utf16.DecodeRune(rune(p.surrogate), r)
+		p.surrogate = 0
+	}
+	p.out.WriteRune(r)
+}
+
+// text 写出文本（跳过的目标组中不输出）
+func (p *rtfParser) text(s string) {
+	p.flush()
+	p.skipChars = 0
+	if !p.group.skip && !p.group.fonttbl {
+		p.out.WriteString(s)
+	}
+}
+
+// flush 按当前代码页解码积累的 \'hh 字节
+func (p *rtfParser) flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+	raw := p.pending
+	p.pending = p.pending[:0]
+	if p.group.skip || p.group.fonttbl {
+		return
+	}
+	codepage := p.group.codepage
+	if codepage == 0 {
+		codepage = p.codepage
+	}
+	if enc := codepageEncoding(codepage); enc != nil {
+		if decoded, err := enc.NewDecoder().Bytes(raw); err == nil {
+			p.out.Write(decoded)
+			return
+		}
+	}
+	p.out.WriteString(string(raw))
+}
+
+// codepageEncoding 返回 Windows 代码页对应的编码，不支持时返回 nil
+func codepageEncoding(codepage int) encoding.Encoding {
+	switch codepage {
+	case 936, 20936, 10008: // GBK、GB2312 及其 Mac 版本
+		return simplifiedchinese.GBK
+	case 54936:
+		return simplifiedchinese.GB18030
+	case 950, 10002:
+		return traditionalchinese.Big5
+	case 932, 10001:
+		return japanese.ShiftJIS
+	case 949, 10003:
+		return korean.EUCKR
+	case 10000:
+		return charmap.Macintosh
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	}
+	return nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func init() {
+	Register(".rtf", &rtfExtractor{})
+}
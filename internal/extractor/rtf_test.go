@@ -0,0 +1,57 @@
+package extractor
+
+import "testing"
+
+func TestRTFExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		rtf     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "文档代码页为 GBK",
+			rtf:  `{\rtf1\ansi\ansicpg936\deff0 \'d6\'d0\'ce\'c4\par \'b1\'a8\'b8\'e6}`,
+			want: "中文\n报告",
+		},
+		{
+			name: "字体字符集为 GB2312",
+			rtf: `{\rtf1\ansi\ansicpg1252{\fonttbl{\f0\fcharset0 Arial;}{\f1\fcharset134 \'cb\'ce\'cc\'e5;}}` +
+				`\f0 Caf\'e9 {\f1 \'ba\'cf\'cd\'ac}\'e9}`,
+			want: "Café 合同é",
+		},
+		{
+			name: "Unicode 转义和替代字符",
+			rtf:  `{\rtf1\ansi\uc1\u21512?\u21516?{\uc2\u25253\'b1\'a8\u21578\'b8\'e6} \u-10179?\u-8704?}`,
+			want: "合同报告 😀",
+		},
+		{
+			name: "跳过字体表、信息和域代码",
+			rtf: `{\rtf1{\fonttbl{\f0 Times;}}{\info{\title 标题}}{\*\generator Writer;}` +
+				`Hello\tab World\par {\field{\*\fldinst HYPERLINK "https://example.com"}{\fldrslt \u38142?\u25509?}}\line A\{B\}}`,
+			want: "Hello\tWorld\n链接\nA{B}",
+		},
+		{
+			name:    "不是 RTF",
+			rtf:     "plain text",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&rtfExtractor{}).Extract(writeTemp(t, "a.rtf", []byte(tt.rtf)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Excel 97-2003 工作簿：Workbook 流由 BIFF8 记录组成，
+// 文本单元格引用共享字符串表（SST），超长的 SST 会拆分到后续的 CONTINUE 记录中
+
+// BIFF8 记录类型
+const (
+	biffBOF        = 0x0809
+	biffFilePass   = 0x002F
+	biffBoundSheet = 0x0085
+	biffSST        = 0x00FC
+	biffContinue   = 0x003C
+	biffLabelSST   = 0x00FD
+	biffLabel      = 0x0204
+	biffNumber     = 0x0203
+	biffRK         = 0x027E
+	biffMulRK      = 0x00BD
+	biffFormula    = 0x0006
+	biffString     = 0x0207 // 公式的字符串结果，紧跟在公式记录之后
+	biffBIFF8      = 0x0600
+)
+
+// biffRecord 一条 BIFF 记录，offset 为记录头在流中的位置
+type biffRecord struct {
+	typ    uint16
+	data   []byte
+	offset int
+}
+
+// xlsExtractor 尽力提取 .xls 中各工作表的名称和单元格文本（字符串、数字和公式的字符串结果），每行一行
+type xlsExtractor struct{}
+
+func (x *xlsExtractor) Extract(path string) (string, error) {
+	cf, f, err := openCFB(path)
+	if err != nil {
+		return "", fmt.Errorf("打开 xls 失败: %v", err)
+	}
+	defer f.Close()
+
+	stream, err := cf.Stream("Workbook")
+	if err != nil {
+		if cf.Has("Book") {
+			return "", fmt.Errorf("不支持 Excel 95 及更早版本的工作簿")
+		}
+		return "", err
+	}
+	records := biffRecords(stream)
+	if len(records) == 0 || records[0].typ != biffBOF || len(records[0].data) < 2 ||
+		binary.LittleEndian.Uint16(records[0].data) != biffBIFF8 {
+		return "", fmt.Errorf("不支持的工作簿版本")
+	}
+
+	// 全局部分：工作表名称和共享字符串表
+	sheets := make(map[int]string) // 工作表 BOF 的位置到名称
+	var sst []string
+	for i := 0; i < len(records); i++ {
+		r := records[i]
+		switch r.typ {
+		case biffFilePass:
+			return "", fmt.Errorf("工作簿已加密")
+		case biffBoundSheet:
+			if len(r.data) >= 8 {
+				name, _ := biffShortString(r.data[6:])
+				sheets[int(binary.LittleEndian.Uint32(r.data))] = name
+			}
+		case biffSST:
+			segments := [][]byte{r.data}
+			for i+1 < len(records) && records[i+1].typ == biffContinue {
+				i++
+				segments = append(segments, records[i].data)
+			}
+			sst = parseSST(segments)
+		}
+	}
+
+	var b strings.Builder
+	row, first := -1, true
+	formulaRow := -1
+	endRow := func() {
+		if !first {
+			b.WriteByte('\n')
+		}
+		first = true
+	}
+	cell := func(r int, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if r != row {
+			endRow()
+			row = r
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		first = false
+	}
+
+	le := binary.LittleEndian
+	for _, r := range records {
+		if b.Len() >= maxContentSize {
+			break
+		}
+		d := r.data
+		switch r.typ {
+		case biffBOF:
+			if name, ok := sheets[r.offset]; ok {
+				endRow()
+				row = -1
+				fmt.Fprintf(&b, "工作表: %s\n", name)
+			}
+		case biffLabelSST:
+			if len(d) >= 10 {
+				if i := int(le.Uint32(d[6:])); i < len(sst) {
+					cell(int(le.Uint16(d)), sst[i])
+				}
+			}
+		case biffLabel:
+			if len(d) >= 8 {
+				text, _ := biffString16(d[6:])
+				cell(int(le.Uint16(d)), text)
+			}
+		case biffNumber:
+			if len(d) >= 14 {
+				cell(int(le.Uint16(d)), formatNumber(math.Float64frombits(le.Uint64(d[6:]))))
+			}
+		case biffRK:
+			if len(d) >= 10 {
+				cell(int(le.Uint16(d)), formatNumber(biffRKValue(le.Uint32(d[6:]))))
+			}
+		case biffMulRK:
+			// 行号、首列，之后每个单元格 6 字节（格式 2 字节 + RK 4 字节），最后是末列
+			if len(d) >= 6 {
+				for off := 4; off+6 <= len(d)-2; off += 6 {
+					cell(int(le.Uint16(d)), formatNumber(biffRKValue(le.Uint32(d[off+2:]))))
+				}
+			}
+		case biffFormula:
+			if len(d) >= 2 {
+				formulaRow = int(le.Uint16(d))
+			}
+		case biffString:
+			text, _ := biffString16(d)
+			cell(formulaRow, text)
+		}
+	}
+	endRow()
+	return limitContent(strings.TrimSpace(b.String())), nil
+}
+
+// Metadata 读取摘要信息中的标题、作者等属性
+func (x *xlsExtractor) Metadata(path string) (map[string]string, error) {
+	return cfbMetadata(path)
+}
+
+// biffRecords 拆分 BIFF 记录流
+func biffRecords(stream []byte) []biffRecord {
+	var records []biffRecord
+	le := binary.LittleEndian
+	for pos := 0; pos+4 <= len(stream); {
+		typ := le.Uint16(stream[pos:])
+		size := int(le.Uint16(stream[pos+2:]))
+		end := pos + 4 + size
+		if end > len(stream) {
+			break
+		}
+		records = append(records, biffRecord{typ: typ, data: stream[pos+4 : end], offset: pos})
+		pos = end
+	}
+	return records
+}
+
+// biffSSTReader 跨 CONTINUE 记录读取 SST：字符串的字符部分跨记录时，
+// 新记录以一个标志字节开头，重新指明字符是否为双字节
+type biffSSTReader struct {
+	segments [][]byte
+	seg      int
+	pos      int
+}
+
+func (r *biffSSTReader) remaining() int {
+	if r.seg >= len(r.segments) {
+		return 0
+	}
+	return len(r.segments[r.seg]) - r.pos
+}
+
+// next 当前记录读完时转到下一条记录
+func (r *biffSSTReader) next() bool {
+	for r.remaining() <= 0 {
+		if r.seg >= len(r.segments) {
+			return false
+		}
+		r.seg++
+		r.pos = 0
+	}
+	return true
+}
+
+// bytes 读取 n 个字节（非字符数据，跨记录时没有标志字节）
+func (r *biffSSTReader) bytes(n int) ([]byte, bool) {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		if !r.next() {
+			return out, false
+		}
+		take := min(n-len(out), r.remaining())
+		out = append(out, r.segments[r.seg][r.pos:r.pos+take]...)
+		r.pos += take
+	}
+	return out, true
+}
+
+// skip 跳过 n 个字节
+func (r *biffSSTReader) skip(n int) bool {
+	for n > 0 {
+		if !r.next() {
+			return false
+		}
+		take := min(n, r.remaining())
+		r.pos += take
+		n -= take
+	}
+	return true
+}
+
+// chars 读取 count 个字符
+func (r *biffSSTReader) chars(count int, wide bool) (string, bool) {
+	var b strings.Builder
+	for count > 0 {
+		if r.remaining() <= 0 {
+			// 字符跨记录：下一条记录的第一个字节为标志
+			if !r.next() {
+				return b.String(), false
+			}
+			wide = r.segments[r.seg][r.pos]&0x01 != 0
+			r.pos++
+			continue
+		}
+		size := 1
+		if wide {
+			size = 2
+		}
+		take := min(count, r.remaining()/size)
+		if take == 0 {
+			// 记录末尾只剩半个字符，视为损坏
+			return b.String(), false
+		}
+		raw := r.segments[r.seg][r.pos : r.pos+take*size]
+		if wide {
+			b.WriteString(utf16LE(raw))
+		} else {
+			b.WriteString(latin1(raw))
+		}
+		r.pos += take * size
+		count -= take
+	}
+	return b.String(), true
+}
+
+// parseSST 解析共享字符串表
+func parseSST(segments [][]byte) []string {
+	if len(segments) == 0 || len(segments[0]) < 8 {
+		return nil
+	}
+	le := binary.LittleEndian
+	unique := int(le.Uint32(segments[0][4:]))
+	r := &biffSSTReader{segments: segments, pos: 8}
+	strs := make([]string, 0, min(unique, 1<<16))
+	for i := 0; i < unique; i++ {
+		head, ok := r.bytes(3)
+		if !ok {
+			break
+		}
+		count := int(le.Uint16(head))
+		flags := head[2]
+		runs, ext := 0, 0
+		if flags&0x08 != 0 {
+			b, ok := r.bytes(2)
+			if !ok {
+				break
+			}
+			runs = int(le.Uint16(b))
+		}
+		if flags&0x04 != 0 {
+			b, ok := r.bytes(4)
+			if !ok {
+				break
+			}
+			ext = int(le.Uint32(b))
+		}
+		text, ok := r.chars(count, flags&0x01 != 0)
+		strs = append(strs, text)
+		if !ok {
+			break
+		}
+		// 跳过富文本格式和注音信息
+		if !r.skip(4*runs + ext) {
+			break
+		}
+	}
+	return strs
+}
+
+// biffShortString 解析 ShortXLUnicodeString（1 字节长度 + 标志 + 字符）
+func biffShortString(d []byte) (string, bool) {
+	if len(d) < 2 {
+		return "", false
+	}
+	return biffChars(d[2:], int(d[0]), d[1]&0x01 != 0)
+}
+
+// biffString16 解析 XLUnicodeString（2 字节长度 + 标志 + 字符）
+func biffString16(d []byte) (string, bool) {
+	if len(d) < 3 {
+		return "", false
+	}
+	return biffChars(d[3:], int(binary.LittleEndian.Uint16(d)), d[2]&0x01 != 0)
+}
+
+func biffChars(d []byte, count int, wide bool) (string, bool) {
+	if wide {
+		if 2*count > len(d) {
+			return utf16LE(d[:len(d)/2*2]), false
+		}
+		return utf16LE(d[:2*count]), true
+	}
+	if count > len(d) {
+		return latin1(d), false
+	}
+	return latin1(d[:count]), true
+}
+
+// biffRKValue 解码 RK 压缩数值：最低位表示除以 100，次低位表示 30 位整数，否则为 double 的高 30 位
+func biffRKValue(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// formatNumber 以最短的形式输出数值（日期等格式化数值按原始数值输出）
+func formatNumber(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// latin1 将单字节字符（Unicode 码位的低 8 位）转为字符串
+func latin1(raw []byte) string {
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func init() {
+	Register(".xls", &xlsExtractor{})
+}
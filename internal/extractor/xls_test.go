@@ -0,0 +1,126 @@
+package extractor
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"unicode/utf16"
+)
+
+func biffRec(typ uint16, data []byte) []byte {
+	rec := binary.LittleEndian.AppendUint16(nil, typ)
+	rec = binary.LittleEndian.AppendUint16(rec, uint16(len(data)))
+	return append(rec, data...)
+}
+
+// biffWide 生成双字节字符
+func biffWide(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = binary.LittleEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// biffCell 生成单元格记录的行号、列号和格式索引
+func biffCell(row, col uint16) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, row)
+	b = binary.LittleEndian.AppendUint16(b, col)
+	return binary.LittleEndian.AppendUint16(b, 0)
+}
+
+// sampleXLS 一个工作表的工作簿，version 为 BOF 中的 BIFF 版本；
+// SST 的第三个字符串跨 CONTINUE 记录，续接部分以标志字节开头
+func sampleXLS(version uint16, encrypted bool) []byte {
+	le := binary.LittleEndian
+	bof := biffRec(biffBOF, append(le.AppendUint16(nil, version), make([]byte, 14)...))
+	eof := biffRec(0x000A, nil)
+
+	sst := le.AppendUint32(nil, 3)
+	sst = le.AppendUint32(sst, 3)
+	sst = append(sst, 2, 0, 1)
+	sst = append(sst, biffWide("名称")...)
+	sst = append(sst, 7, 0, 0)
+	sst = append(sst, "Revenue"...)
+	sst = append(sst, 4, 0, 1)
+	sst = append(sst, biffWide("季度")...)
+	cont := append([]byte{1}, biffWide("汇总")...)
+
+	sheetName := append([]byte{2, 1}, biffWide("数据")...)
+	boundSheet := append(make([]byte, 6), sheetName...)
+
+	globals := append([]byte(nil), bof...)
+	if encrypted {
+		globals = append(globals, biffRec(biffFilePass, make([]byte, 6))...)
+	}
+	boundAt := len(globals) + 4
+	globals = append(globals, biffRec(biffBoundSheet, boundSheet)...)
+	globals = append(globals, biffRec(biffSST, sst)...)
+	globals = append(globals, biffRec(biffContinue, cont)...)
+	globals = append(globals, eof...)
+	le.PutUint32(globals[boundAt:], uint32(len(globals)))
+
+	number := le.AppendUint64(biffCell(1, 1), math.Float64bits(1234.5))
+	rk := le.AppendUint32(biffCell(1, 2), 42<<2|0x02)
+	formula := append(biffCell(2, 0), make([]byte, 14)...)
+	result := append([]byte{2, 0, 1}, biffWide("合计")...)
+
+	sheet := append([]byte(nil), bof...)
+	sheet = append(sheet, biffRec(biffLabelSST, le.AppendUint32(biffCell(0, 0), 0))...)
+	sheet = append(sheet, biffRec(biffLabelSST, le.AppendUint32(biffCell(0, 1), 1))...)
+	sheet = append(sheet, biffRec(biffLabelSST, le.AppendUint32(biffCell(1, 0), 2))...)
+	sheet = append(sheet, biffRec(biffNumber, number)...)
+	sheet = append(sheet, biffRec(biffRK, rk)...)
+	sheet = append(sheet, biffRec(biffFormula, formula)...)
+	sheet = append(sheet, biffRec(biffString, result)...)
+	sheet = append(sheet, eof...)
+	return append(globals, sheet...)
+}
+
+func TestXLSExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		streams []cfbStream
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "共享字符串、数值和公式结果",
+			streams: []cfbStream{{"Workbook", sampleXLS(biffBIFF8, false)}},
+			want:    "工作表: 数据\n名称 Revenue\n季度汇总 1234.5 42\n合计",
+		},
+		{
+			name:    "已加密",
+			streams: []cfbStream{{"Workbook", sampleXLS(biffBIFF8, true)}},
+			wantErr: true,
+		},
+		{
+			name:    "不是 BIFF8",
+			streams: []cfbStream{{"Workbook", sampleXLS(0x0500, false)}},
+			wantErr: true,
+		},
+		{
+			name:    "Excel 95 工作簿",
+			streams: []cfbStream{{"Book", sampleXLS(0x0500, false)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.xls", buildCFB(tt.streams...))
+			got, err := (&xlsExtractor{}).Extract(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
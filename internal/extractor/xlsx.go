@@ -0,0 +1,287 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// xlsxExtractor 提取 xlsx 中各工作表的名称和单元格文本，按工作表顺序、逐行输出
+// structured 为 true 时保留行列结构：每行单元格以制表符分隔，空单元格保留占位，列与表头对齐；
+// 否则只输出非空单元格，以空格分隔
+type xlsxExtractor struct {
+	structured bool
+}
+
+// xlsxSheet 工作簿中的一个工作表
+type xlsxSheet struct {
+	Name string
+	Path string // 在 zip 中的路径，如 xl/worksheets/sheet1.xml
+}
+
+func (x *xlsxExtractor) Extract(filePath string) (string, error) {
+	zf, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("打开 xlsx 失败: %v", err)
+	}
+	defer zf.Close()
+
+	files := make(map[string]*zip.File, len(zf.File))
+	for _, f := range zf.File {
+		files[f.Name] = f
+	}
+	sheets, err := xlsxSheets(files)
+	if err != nil {
+		return "", err
+	}
+	shared, err := xlsxSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, sheet := range sheets {
+		if b.Len() >= maxContentSize {
+			break
+		}
+		f := files[sheet.Path]
+		if f == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "工作表: %s\n", sheet.Name)
+		if err := x.writeSheet(&b, f, shared); err != nil {
+			return "", fmt.Errorf("读取工作表 %s 失败: %v", sheet.Name, err)
+		}
+	}
+	return limitContent(strings.TrimSpace(b.String())), nil
+}
+
+// Metadata 读取 docProps/core.xml 中的标题、作者等属性
+func (x *xlsxExtractor) Metadata(filePath string) (map[string]string, error) {
+	return ooxmlCoreProperties(filePath)
+}
+
+// writeSheet 逐行写出工作表的单元格文本，内容达到上限后停止读取
+func (x *xlsxExtractor) writeSheet(b *strings.Builder, f *zip.File, shared []string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var (
+		row      []string // 当前行，下标为列号
+		cellCol  int      // 当前单元格的列号
+		cellType string
+		value    strings.Builder
+		inValue  bool // 位于 <v> 或 <is><t> 中
+		inPhonet bool // 位于注音 <rPh> 中，其文本不输出
+		nextCol  int  // 单元格未标注位置时按顺序排列
+	)
+	decoder := xml.NewDecoder(rc)
+	for b.Len() < maxContentSize {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = row[:0]
+				nextCol = 0
+			case "c":
+				cellType = xmlAttr(t, "t")
+				cellCol = nextCol
+				if col, ok := xlsxColumn(xmlAttr(t, "r")); ok {
+					cellCol = col
+				}
+				nextCol = cellCol + 1
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			case "rPh":
+				inPhonet = true
+			}
+		case xml.CharData:
+			if inValue && !inPhonet {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "rPh":
+				inPhonet = false
+			case "c":
+				text := strings.TrimSpace(xlsxCellText(cellType, value.String(), shared))
+				if text != "" {
+					for len(row) <= cellCol {
+						row = append(row, "")
+					}
+					row[cellCol] = text
+				}
+			case "row":
+				x.writeRow(b, row)
+			}
+		}
+	}
+	return nil
+}
+
+// writeRow 写出一行，空行跳过
+func (x *xlsxExtractor) writeRow(b *strings.Builder, row []string) {
+	if x.structured {
+		if len(row) > 0 {
+			b.WriteString(strings.Join(row, "\t"))
+			b.WriteByte('\n')
+		}
+		return
+	}
+	first := true
+	for _, text := range row {
+		if text == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+		first = false
+	}
+	if !first {
+		b.WriteByte('\n')
+	}
+}
+
+// xlsxCellText 按单元格类型换算出显示文本：共享字符串取下标对应的文本，布尔值输出 TRUE/FALSE
+func xlsxCellText(cellType, raw string, shared []string) string {
+	switch cellType {
+	case "s":
+		var i int
+		if _, err := fmt.Sscanf(raw, "%d", &i); err == nil && i >= 0 && i < len(shared) {
+			return shared[i]
+		}
+		return ""
+	case "b":
+		if strings.TrimSpace(raw) == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return raw
+}
+
+// xlsxColumn 解析单元格引用（如 "AB12"）中的列号，从 0 开始
+func xlsxColumn(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// xlsxSheets 按工作簿中的顺序列出工作表，通过 workbook.xml.rels 找到各工作表的文件
+func xlsxSheets(files map[string]*zip.File) ([]xlsxSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readZipXML(files["xl/workbook.xml"], &workbook); err != nil {
+		return nil, fmt.Errorf("读取 workbook.xml 失败: %v", err)
+	}
+	rels, err := ooxmlRelationships(files, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for i, s := range workbook.Sheets {
+		target := rels[s.ID].Target
+		if target == "" {
+			// 缺少关系文件时按默认命名推断
+			target = fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		}
+		sheets = append(sheets, xlsxSheet{Name: s.Name, Path: target})
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings 读取共享字符串表；富文本由多个 <r><t> 拼接，注音 <rPh> 不计入
+func xlsxSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取 sharedStrings.xml 失败: %v", err)
+	}
+	defer rc.Close()
+
+	var (
+		shared   []string
+		current  strings.Builder
+		inText   bool
+		inPhonet bool
+	)
+	decoder := xml.NewDecoder(rc)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 sharedStrings.xml 失败: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhonet = true
+			}
+		case xml.CharData:
+			if inText && !inPhonet {
+				current.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				shared = append(shared, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonet = false
+			}
+		}
+	}
+	return shared, nil
+}
+
+func init() {
+	// 设置环境变量 XLSX_STRUCTURED=1 时保留行列结构，输出不同，计入缓存键
+	x := &xlsxExtractor{structured: os.Getenv("XLSX_STRUCTURED") == "1"}
+	if x.structured {
+		variants = append(variants, "xlsx-structured")
+	}
+	Register(".xlsx", x)
+}
@@ -0,0 +1,100 @@
+package extractor
+
+import "testing"
+
+const (
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="汇总" sheetId="2" r:id="rId2"/><sheet name="明细" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`
+	// 第一项为富文本，带日文注音
+	xlsxShared = `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="2" uniqueCount="2">
+<si><r><t>季度</t></r><r><rPr><b/></rPr><t>报告</t></r><rPh sb="0" eb="2"><t>きせつ</t></rPh></si>
+<si><t xml:space="preserve"> Revenue </t></si>
+</sst>`
+	// 共享字符串、数值、布尔值、内联字符串，越界的共享字符串下标和中间空列
+	xlsxSummary = `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2"><v>1234.5</v></c><c r="B2" t="b"><v>1</v></c><c r="C2" t="inlineStr"><is><t>备注</t></is></c></row>
+<row r="3"><c r="C3" t="s"><v>99</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>x</t></is></c><c r="C4" t="inlineStr"><is><t>y</t></is></c></row>
+</sheetData></worksheet>`
+	// 单元格未标注位置
+	xlsxDetail = `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row><c t="inlineStr"><is><t>A</t></is></c><c t="b"><v>0</v></c></row>
+</sheetData></worksheet>`
+)
+
+func TestXLSXExtract(t *testing.T) {
+	full := buildZip(
+		zipFile{"xl/workbook.xml", xlsxWorkbook},
+		zipFile{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		zipFile{"xl/sharedStrings.xml", xlsxShared},
+		zipFile{"xl/worksheets/sheet1.xml", xlsxDetail},
+		zipFile{"xl/worksheets/sheet2.xml", xlsxSummary},
+	)
+	tests := []struct {
+		name       string
+		data       []byte
+		structured bool
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "按工作簿顺序输出，共享字符串跳过注音",
+			data: full,
+			want: "工作表: 汇总\n季度报告 Revenue\n1234.5 TRUE 备注\nx y\n工作表: 明细\nA FALSE",
+		},
+		{
+			name:       "结构化输出保留空列",
+			data:       full,
+			structured: true,
+			want:       "工作表: 汇总\n季度报告\tRevenue\n1234.5\tTRUE\t备注\nx\t\ty\n工作表: 明细\nA\tFALSE",
+		},
+		{
+			name: "缺少关系文件时按默认命名查找工作表",
+			data: buildZip(
+				zipFile{"xl/workbook.xml", xlsxWorkbook},
+				zipFile{"xl/sharedStrings.xml", xlsxShared},
+				zipFile{"xl/worksheets/sheet1.xml", xlsxDetail},
+			),
+			want: "工作表: 汇总\nA FALSE",
+		},
+		{
+			name:    "缺少 workbook.xml",
+			data:    buildZip(zipFile{"xl/worksheets/sheet1.xml", xlsxDetail}),
+			wantErr: true,
+		},
+		{
+			name:    "不是 zip",
+			data:    []byte("plain text"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTemp(t, "a.xlsx", tt.data)
+			got, err := (&xlsxExtractor{structured: tt.structured}).Extract(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Extract() = %q，期望返回错误", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Extract() = %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
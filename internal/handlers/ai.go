@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/ai"
+)
+
+// AIStatusHandler 返回各 AI 服务的熔断状态、调用统计和保护参数
+func AIStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "providers": ai.Stats()})
+}
+
+// ResetAIBreakersHandler 手动关闭所有熔断器，服务恢复后无需等待冷却
+func ResetAIBreakersHandler(c *gin.Context) {
+	ai.ResetBreakers()
+	c.JSON(http.StatusOK, gin.H{"success": true, "providers": ai.Stats()})
+}
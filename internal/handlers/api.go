@@ -2,25 +2,35 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"file-classifier/internal/catalog"
 	"file-classifier/internal/config"
 	"file-classifier/internal/models"
 	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+	"file-classifier/internal/usage"
 )
 
-// AddCategoryHandler 添加新分类
+// AddCategoryHandler 添加新分类，指定 parent 时作为其子分类
 func AddCategoryHandler(c *gin.Context) {
 	var request struct {
-		CategoryName string `json:"categoryName" binding:"required"`
-		Username     string `json:"username" binding:"required"`
+		CategoryName string   `json:"categoryName" binding:"required"`
+		Username     string   `json:"username" binding:"required"`
+		Parent       string   `json:"parent"`
+		Keywords     []string `json:"keywords"`
+		Description  string   `json:"description"`
+		Icon         string   `json:"icon"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -31,34 +41,33 @@ func AddCategoryHandler(c *gin.Context) {
 		return
 	}
 
-	// 检查新增分类数量限制（最多3个）
-	keywords := config.GetClassificationKeywords()
-	newCategoryCount := 0
-	for categoryName := range keywords {
-		if !config.IsPredefinedCategory(categoryName) {
-			newCategoryCount++
-		}
+	// 未指定关键词时使用用户名作为关键词
+	keywordList := request.Keywords
+	if len(keywordList) == 0 {
+		keywordList = []string{request.Username}
+	}
+	category := taxonomy.Category{
+		Name:        strings.TrimSpace(request.CategoryName),
+		Parent:      strings.TrimSpace(request.Parent),
+		Description: request.Description,
+		Icon:        request.Icon,
+	}
+	for _, word := range keywordList {
+		category.Keywords = append(category.Keywords, taxonomy.KeywordRule{Word: word, Weight: 1})
 	}
 
-	if newCategoryCount >= 3 {
+	// 添加新分类；数量上限、层数等由分类策略校验
+	if err := taxonomy.AddCategory(category); err != nil {
 		c.JSON(http.StatusBadRequest, models.Response{
 			Success: false,
-			Error:   "新增分类数量已达上限（最多3个），请删除一些分类后再添加",
+			Error:   err.Error(),
 		})
 		return
 	}
 
-	// 使用用户名作为关键词
-	keywordList := []string{request.Username}
-
-	// 添加新分类
-	config.AddCategory(request.CategoryName, keywordList)
-
-	// 重新扫描文件以应用新分类
+	// 重新扫描文件以应用新分类：与扫描接口一样经过完整的分类流水线
+	account := usage.Account{Batch: uuid.NewString(), User: currentUsername(c)}
 	go func() {
-		// 在后台重新扫描文件
-		service.ResetClassificationStats()
-
 		// 检查uploads目录是否存在
 		if _, err := os.Stat(config.UploadDir); os.IsNotExist(err) {
 			return
@@ -70,64 +79,78 @@ func AddCategoryHandler(c *gin.Context) {
 				return err
 			}
 
-			// 跳过目录本身
-			if path == config.UploadDir {
+			// 只处理文件，跳过目录
+			if info.IsDir() {
 				return nil
 			}
+			relPath, err := filepath.Rel(config.UploadDir, path)
+			if err != nil {
+				return err
+			}
 
-			// 只处理文件，跳过目录
-			if !info.IsDir() {
-				// 获取相对路径
-				relPath, err := filepath.Rel(config.UploadDir, path)
-				if err != nil {
-					return err
-				}
-
-				// 分类文件
-				category := service.ClassifyByFilename(info.Name())
-
-				// 创建文件信息
-				fileInfo := models.FileInfo{
-					Name:     info.Name(),
-					Path:     relPath,
-					Size:     info.Size(),
-					Type:     "filename",
-					Category: category,
-					ModTime:  info.ModTime(),
-				}
-
-				// 添加到分类
-				service.AddFileToCategory(category, fileInfo)
+			// 以目录中已有的记录为基础，未重新计算的字段（创建时间、用户标签等）保持不变
+			fileInfo, exists := catalog.Get(relPath)
+			if !exists {
+				fileInfo = models.FileInfo{Name: info.Name(), Path: relPath}
 			}
+			fileInfo.Size = info.Size()
+			fileInfo.ModTime = info.ModTime()
+
+			service.ClassifyFile(path, &fileInfo, account)
+			service.AddFileToCategory(fileInfo.Category, fileInfo)
 			return nil
 		})
 	}()
 
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
-		Message: fmt.Sprintf("分类 '%s' 添加成功，关键词: %s", request.CategoryName, request.Username),
+		Message: fmt.Sprintf("分类 '%s' 添加成功，关键词: %s", category.Name, strings.Join(keywordList, ", ")),
 	})
 }
 
-// GetCategoriesHandler 获取所有分类
+// GetCategoriesHandler 获取分类树及分类策略
 func GetCategoriesHandler(c *gin.Context) {
-	categories := config.GetClassificationKeywords()
+	tax := taxonomy.Current()
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
-		"categories": categories,
+		"categories": tax.Sorted(),
+		"policy":     tax.Policy,
+	})
+}
+
+// UpdateCategoryPolicyHandler 修改分类策略（自定义分类上限、最大层数）
+func UpdateCategoryPolicyHandler(c *gin.Context) {
+	var policy taxonomy.Policy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if policy.MaxCustomCategories < 0 || policy.MaxDepth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "策略取值不能为负数"})
+		return
+	}
+
+	err := taxonomy.Update(func(t *taxonomy.Taxonomy) error {
+		t.Policy = policy
+		return nil
 	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "policy": policy})
 }
 
-// StatsHandler 获取分类统计
+// StatsHandler 获取分类统计（子分类汇总到顶级分类）
 func StatsHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, config.GetClassificationStats())
+	c.JSON(http.StatusOK, service.GetClassificationStats())
 }
 
-// FilesHandler 获取指定分类的文件列表
+// FilesHandler 获取指定分类（任意层级，含子分类）的文件列表
 func FilesHandler(c *gin.Context) {
 	category := c.Param("category")
 
-	if stats, exists := config.ClassificationStats[category]; exists {
+	if stats, exists := service.GetCategoryStats(category); exists {
 		c.JSON(http.StatusOK, stats)
 	} else {
 		c.JSON(http.StatusNotFound, gin.H{"error": "分类不存在"})
@@ -136,9 +159,6 @@ func FilesHandler(c *gin.Context) {
 
 // ScanUploadsHandler 扫描uploads文件夹并分类
 func ScanUploadsHandler(c *gin.Context) {
-	// 重置统计
-	service.ResetClassificationStats()
-
 	// 检查uploads目录是否存在
 	if _, err := os.Stat(config.UploadDir); os.IsNotExist(err) {
 		c.JSON(http.StatusOK, models.Response{
@@ -149,7 +169,7 @@ func ScanUploadsHandler(c *gin.Context) {
 				Processed:           0,
 				FirstStepClassified: 0,
 				AIClassified:        0,
-				Classifications:     config.ClassificationStats,
+				Classifications:     service.GetClassificationStats(),
 			},
 		})
 		return
@@ -158,6 +178,8 @@ func ScanUploadsHandler(c *gin.Context) {
 	// 遍历uploads目录
 	var files []string
 	var fileSizes map[string]int64 = make(map[string]int64)
+	var modTimes = make(map[string]time.Time)
+	var present = make(map[string]bool)
 
 	err := filepath.Walk(config.UploadDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -178,6 +200,8 @@ func ScanUploadsHandler(c *gin.Context) {
 			}
 			files = append(files, relativePath)
 			fileSizes[relativePath] = info.Size()
+			modTimes[relativePath] = info.ModTime()
+			present[relativePath] = true
 		}
 
 		return nil
@@ -191,14 +215,22 @@ func ScanUploadsHandler(c *gin.Context) {
 		return
 	}
 
+	// 清理磁盘上已不存在的文件记录
+	if removed, err := catalog.Prune(present); err != nil {
+		log.Printf("清理文件目录失败: %v", err)
+	} else if removed > 0 {
+		log.Printf("已从文件目录移除 %d 个不存在的文件", removed)
+	}
+
 	// 并发处理找到的文件
 	results := &models.UploadResult{
 		Total:               len(files),
 		Processed:           0,
 		FirstStepClassified: 0,
 		AIClassified:        0,
-		Classifications:     config.ClassificationStats,
+		BatchID:             uuid.NewString(),
 	}
+	account := usage.Account{Batch: results.BatchID, User: currentUsername(c)}
 
 	// 创建并发控制
 	maxConcurrent := 30 // 最大并发数
@@ -216,28 +248,20 @@ func ScanUploadsHandler(c *gin.Context) {
 			defer func() { <-semaphore }()
 
 			filename := filepath.Base(filePath)
-			category := service.ClassifyByFilename(filename)
 
 			fileInfo := models.FileInfo{
-				Name: filename,
-				Path: filePath,            // 使用相对路径
-				Size: fileSizes[filePath], // 使用获取到的文件大小
+				Name:    filename,
+				Path:    filePath,            // 使用相对路径
+				Size:    fileSizes[filePath], // 使用获取到的文件大小
+				ModTime: modTimes[filePath],
 			}
 
+			service.ClassifyFile(filepath.Join(config.UploadDir, filePath), &fileInfo, account)
+			service.AddFileToCategory(fileInfo.Category, fileInfo)
+
 			// 使用互斥锁保护共享数据
 			mu.Lock()
-			if category != "未分类" {
-				fileInfo.Type = "filename"
-				service.AddFileToCategory(category, fileInfo)
-				results.FirstStepClassified++
-			} else {
-				// Second step: AI classification
-				aiCategory := service.ClassifyByAI(filename)
-				fileInfo.Type = "AI"
-				service.AddFileToCategory(aiCategory, fileInfo)
-				results.AIClassified++
-			}
-			results.Processed++
+			results.Count(fileInfo.Type)
 			mu.Unlock()
 
 		}(filePath)
@@ -246,7 +270,9 @@ func ScanUploadsHandler(c *gin.Context) {
 	// 等待所有goroutine完成
 	wg.Wait()
 
-	results.Classifications = config.ClassificationStats
+	results.Classifications = service.GetClassificationStats()
+	batchUsage := usage.Total(usage.Filter{Batch: results.BatchID})
+	results.Usage = &batchUsage
 
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
@@ -261,32 +287,30 @@ func AllFilesHandler(c *gin.Context) {
 	sortBy := c.DefaultQuery("sort", "time")         // 排序方式：time(默认), size
 	order := c.DefaultQuery("order", "desc")         // 排序顺序：desc(默认), asc
 	filterCategory := c.DefaultQuery("category", "") // 分类筛选：空表示全部
+	filterTag := c.DefaultQuery("tag", "")           // 标签筛选：空表示全部
 
 	var allFiles []models.FileInfo
 
-	// 遍历所有分类，收集文件
-	for categoryName, categoryStats := range config.ClassificationStats {
-		// 如果指定了分类筛选，只返回对应分类的文件
-		if filterCategory != "" && categoryName != filterCategory {
+	// 从文件目录收集文件
+	for _, file := range catalog.List() {
+		// 如果指定了分类筛选，只返回对应分类（含子分类）的文件
+		if filterCategory != "" && !service.InCategory(file.Category, filterCategory) {
+			continue
+		}
+		if filterTag != "" && !file.HasTag(filterTag) {
 			continue
 		}
 
-		for _, file := range categoryStats.Files {
-			// 为每个文件添加分类信息
-			fileWithCategory := file
-			fileWithCategory.Category = categoryName
-
-			// 获取文件的修改时间
-			fullPath := filepath.Join(config.UploadDir, file.Path)
-			if fileInfo, err := os.Stat(fullPath); err == nil {
-				fileWithCategory.ModTime = fileInfo.ModTime()
-			} else {
-				// 如果无法获取文件信息，使用当前时间
-				fileWithCategory.ModTime = time.Now()
-			}
-
-			allFiles = append(allFiles, fileWithCategory)
+		// 获取文件的修改时间
+		fullPath := filepath.Join(config.UploadDir, file.Path)
+		if fileInfo, err := os.Stat(fullPath); err == nil {
+			file.ModTime = fileInfo.ModTime()
+		} else if file.ModTime.IsZero() {
+			// 如果无法获取文件信息，使用当前时间
+			file.ModTime = time.Now()
 		}
+
+		allFiles = append(allFiles, file)
 	}
 
 	// 根据指定方式排序
@@ -102,3 +102,15 @@ func LogoutHandler(c *gin.Context) {
 	})
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
+
+// currentUsername 返回当前登录用户名，未登录时返回 "anonymous"
+func currentUsername(c *gin.Context) string {
+	cookie, err := c.Request.Cookie("sid")
+	if err != nil || cookie.Value == "" {
+		return "anonymous"
+	}
+	if sess, ok := auth.GetSession(cookie.Value); ok {
+		return sess.Username
+	}
+	return "anonymous"
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/cache"
+	"file-classifier/internal/config"
+)
+
+// CacheStatsHandler 返回分类缓存的条目数、命中率和文件大小
+func CacheStatsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "cache": cache.GetStats()})
+}
+
+// InvalidateCacheHandler 清除缓存：kind 为 text 或 ai 时只清除该类型，
+// 提供 paths（相对 uploads 的路径）时只清除这些文件的缓存，否则清除全部
+func InvalidateCacheHandler(c *gin.Context) {
+	var request struct {
+		Kind  string   `json:"kind"`
+		Paths []string `json:"paths"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if request.Kind != "" && request.Kind != cache.KindText && request.Kind != cache.KindAI {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "kind 只能为 text 或 ai"})
+		return
+	}
+
+	var hashes []string
+	failed := map[string]string{}
+	for _, path := range request.Paths {
+		hash, err := cache.HashFile(filepath.Join(config.UploadDir, filepath.Clean("/"+path)))
+		if err != nil {
+			failed[path] = err.Error()
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(request.Paths) > 0 && len(hashes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "指定的文件都无法读取", "failed": failed})
+		return
+	}
+
+	removed, err := cache.Invalidate(request.Kind, hashes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "removed": removed, "failed": failed, "cache": cache.GetStats()})
+}
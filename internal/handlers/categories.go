@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+)
+
+// categoryStatus 将分类管理错误映射为 HTTP 状态码
+func categoryStatus(err error) int {
+	switch {
+	case errors.Is(err, taxonomy.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, taxonomy.ErrCategoryProtected):
+		return http.StatusForbidden
+	case errors.Is(err, taxonomy.ErrCategoryExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// DeleteCategoryHandler 删除分类，文件移到 reassignTo（缺省为未分类）
+func DeleteCategoryHandler(c *gin.Context) {
+	var request struct {
+		CategoryName string `json:"categoryName" binding:"required"`
+		ReassignTo   string `json:"reassignTo"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	change, err := service.DeleteCategory(request.CategoryName, strings.TrimSpace(request.ReassignTo), currentUsername(c))
+	if err != nil {
+		c.JSON(categoryStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "分类删除成功", "moved": change})
+}
+
+// RenameCategoryHandler 重命名分类并迁移已有文件记录
+func RenameCategoryHandler(c *gin.Context) {
+	var request struct {
+		NewName string `json:"newName" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	change, err := service.RenameCategory(c.Param("name"), strings.TrimSpace(request.NewName), currentUsername(c))
+	if err != nil {
+		c.JSON(categoryStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "分类重命名成功", "moved": change})
+}
+
+// UpdateCategoryHandler 修改分类的关键词、描述、图标、显示顺序和保护标记，未提供的字段保持不变
+func UpdateCategoryHandler(c *gin.Context) {
+	var request struct {
+		Keywords    *[]taxonomy.KeywordRule `json:"keywords"`
+		Description *string                 `json:"description"`
+		Icon        *string                 `json:"icon"`
+		Order       *int                    `json:"order"`
+		Protected   *bool                   `json:"protected"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if request.Keywords != nil {
+		for _, rule := range *request.Keywords {
+			if strings.TrimSpace(rule.Word) == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "关键词不能为空"})
+				return
+			}
+		}
+	}
+
+	category, err := taxonomy.EditCategory(c.Param("name"), func(cat *taxonomy.Category) {
+		if request.Keywords != nil {
+			cat.Keywords = *request.Keywords
+		}
+		if request.Description != nil {
+			cat.Description = *request.Description
+		}
+		if request.Icon != nil {
+			cat.Icon = *request.Icon
+		}
+		if request.Order != nil {
+			cat.Order = *request.Order
+		}
+		if request.Protected != nil {
+			cat.Protected = *request.Protected
+		}
+	})
+	if err != nil {
+		c.JSON(categoryStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "category": category})
+}
+
+// ReorderCategoriesHandler 按列表顺序设置分类的显示顺序
+func ReorderCategoriesHandler(c *gin.Context) {
+	var request struct {
+		Order []string `json:"order" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	if err := taxonomy.Reorder(request.Order); err != nil {
+		c.JSON(categoryStatus(err), gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "categories": taxonomy.Current().Sorted()})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/evaluation"
+	"file-classifier/internal/service"
+	"file-classifier/internal/utils"
+)
+
+// EvaluateHandler 在服务器上的标注语料目录（每个子目录为一个分类）上评估分类流水线
+// dir 为语料根目录（EVALUATION_DIR，默认 corpus）下的相对路径，不能是绝对路径或跳出根目录
+// pipeline 缺省为当前生效的配置；提供 compare 时对比两个配置；请求中的配置只能指定各级的分类器和阈值
+// 默认返回 JSON，?format=csv&table=metrics|confusion|misclassified|comparison 返回 CSV
+func EvaluateHandler(c *gin.Context) {
+	var request struct {
+		Dir      string                  `json:"dir" binding:"required"`
+		Pipeline *service.PipelineConfig `json:"pipeline"`
+		Compare  *service.PipelineConfig `json:"compare"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	dir, err := corpusDir(request.Dir)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	// 请求只能选择分类器和阈值，选项沿用服务器上的配置，避免把 AI 密钥发往请求指定的地址或读取任意文件
+	baseline := service.CurrentPipelineConfig()
+	if request.Pipeline != nil {
+		if baseline, err = service.RequestPipelineConfig(*request.Pipeline); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+	}
+	var candidate service.PipelineConfig
+	if request.Compare != nil {
+		if candidate, err = service.RequestPipelineConfig(*request.Compare); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+	}
+
+	var (
+		report     *evaluation.Report
+		comparison *evaluation.Comparison
+	)
+	if request.Compare != nil {
+		comparison, err = service.EvaluateCompare(dir, baseline, candidate)
+	} else {
+		report, err = service.Evaluate(dir, baseline, "pipeline")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	if strings.ToLower(c.Query("format")) == "csv" {
+		table := c.DefaultQuery("table", evaluation.TableMetrics)
+		var buf bytes.Buffer
+		// 写入 BOM 便于 Excel 正确识别中文
+		buf.WriteString("\ufeff")
+		if comparison != nil {
+			err = comparison.WriteCSV(&buf, table)
+		} else {
+			err = report.WriteCSV(&buf, table)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=evaluation_"+table+".csv")
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	if comparison != nil {
+		c.JSON(http.StatusOK, gin.H{"success": true, "comparison": comparison})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "report": report})
+}
+
+// corpusDir 将请求中的语料名解析为语料根目录下的路径
+func corpusDir(name string) (string, error) {
+	name = filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("语料目录必须是 %s 下的相对路径", utils.GetEvaluationDir())
+	}
+	return filepath.Join(utils.GetEvaluationDir(), name), nil
+}
@@ -144,6 +144,10 @@ func getContentType(ext string) string {
 		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		".ppt":  "application/vnd.ms-powerpoint",
 		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		".odt":  "application/vnd.oasis.opendocument.text",
+		".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+		".odp":  "application/vnd.oasis.opendocument.presentation",
+		".rtf":  "application/rtf",
 		".txt":  "text/plain",
 		".md":   "text/markdown",
 		".log":  "text/plain",
@@ -151,6 +155,9 @@ func getContentType(ext string) string {
 		".json": "application/json",
 		".xml":  "application/xml",
 		".html": "text/html",
+		".htm":  "text/html",
+		".eml":  "message/rfc822",
+		".mbox": "application/mbox",
 		".css":  "text/css",
 		".js":   "application/javascript",
 		".jpg":  "image/jpeg",
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/bayes"
+	"file-classifier/internal/service"
+)
+
+// TrainModelHandler 使用手动确认/纠正过的文件重新训练本地模型
+func TrainModelHandler(c *gin.Context) {
+	var request struct {
+		TestRatio *float64 `json:"testRatio"` // 留出集比例，默认 0.2
+	}
+	// 请求体可为空
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+			return
+		}
+	}
+
+	testRatio := 0.2
+	if request.TestRatio != nil {
+		testRatio = *request.TestRatio
+	}
+
+	report, err := service.TrainModel(testRatio)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error(), "report": report})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "report": report})
+}
+
+// ModelInfoHandler 返回当前模型的训练信息
+func ModelInfoHandler(c *gin.Context) {
+	model := bayes.Current()
+	if model == nil {
+		c.JSON(http.StatusOK, gin.H{"success": true, "trained": false})
+		return
+	}
+
+	categories := make(map[string]int)
+	for name, cm := range model.Categories {
+		categories[name] = cm.Docs
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"trained":     true,
+		"trainedAt":   model.TrainedAt,
+		"samples":     model.Docs,
+		"vocabulary":  model.Vocabulary,
+		"accuracy":    model.Accuracy,
+		"testSamples": model.TestSamples,
+		"categories":  categories,
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+)
+
+// ReclassifyHandler 手动将一个或多个文件归入指定分类
+// 手动分类会记录操作人、时间和原分类，后续重新扫描时保留
+func ReclassifyHandler(c *gin.Context) {
+	var request struct {
+		Paths    []string `json:"paths" binding:"required"`
+		Category string   `json:"category" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if len(request.Paths) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请至少指定一个文件"})
+		return
+	}
+
+	if !taxonomy.Current().Has(request.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "分类不存在: " + request.Category})
+		return
+	}
+
+	updated, failed := service.ReclassifyFiles(request.Paths, request.Category, currentUsername(c))
+
+	// 部分文件失败不影响其他文件，按 updated 和 failed 判断结果
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"updated": len(updated),
+		"files":   updated,
+		"failed":  failed,
+	})
+}
+
+// OverrideHistoryHandler 查询单个文件的手动分类历史
+func OverrideHistoryHandler(c *gin.Context) {
+	path := c.Query("path")
+	if path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "文件路径不能为空"})
+		return
+	}
+
+	info, ok := catalog.Get(path)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "文件不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"path":     path,
+		"category": info.Category,
+		"override": info.Override,
+		"history":  catalog.History(path),
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/rules"
+	"file-classifier/internal/taxonomy"
+)
+
+// ruleRequest 新增/修改规则的请求体
+type ruleRequest struct {
+	Name       string           `json:"name" binding:"required"`
+	Category   string           `json:"category"` // 为空时仅打标签
+	Tags       []string         `json:"tags"`
+	Priority   int              `json:"priority"`
+	Enabled    *bool            `json:"enabled"` // 缺省为启用
+	Conditions rules.Conditions `json:"conditions"`
+}
+
+// bindRule 解析并校验规则请求，失败时已写入响应
+func bindRule(c *gin.Context) (rules.Rule, bool) {
+	var request ruleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return rules.Rule{}, false
+	}
+
+	if request.Category != "" && !taxonomy.Current().Has(request.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "分类不存在: " + request.Category})
+		return rules.Rule{}, false
+	}
+
+	rule := rules.Rule{
+		Name:       request.Name,
+		Category:   request.Category,
+		Tags:       request.Tags,
+		Priority:   request.Priority,
+		Enabled:    request.Enabled == nil || *request.Enabled,
+		Conditions: request.Conditions,
+	}
+	if err := rules.Validate(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return rules.Rule{}, false
+	}
+	return rule, true
+}
+
+// ListRulesHandler 获取所有分类规则
+func ListRulesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "rules": rules.List()})
+}
+
+// GetRuleHandler 获取单条规则
+func GetRuleHandler(c *gin.Context) {
+	rule, err := rules.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "rule": rule})
+}
+
+// CreateRuleHandler 新增规则
+func CreateRuleHandler(c *gin.Context) {
+	rule, ok := bindRule(c)
+	if !ok {
+		return
+	}
+	created, err := rules.Create(rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	taxonomy.SnapshotRules()
+	c.JSON(http.StatusOK, gin.H{"success": true, "rule": created})
+}
+
+// UpdateRuleHandler 修改规则
+func UpdateRuleHandler(c *gin.Context) {
+	rule, ok := bindRule(c)
+	if !ok {
+		return
+	}
+	updated, err := rules.Update(c.Param("id"), rule)
+	if errors.Is(err, rules.ErrRuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	taxonomy.SnapshotRules()
+	c.JSON(http.StatusOK, gin.H{"success": true, "rule": updated})
+}
+
+// DeleteRuleHandler 删除规则
+func DeleteRuleHandler(c *gin.Context) {
+	err := rules.Delete(c.Param("id"))
+	if errors.Is(err, rules.ErrRuleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	taxonomy.SnapshotRules()
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/models"
+	"file-classifier/internal/service"
+)
+
+// TagCountsHandler 获取所有标签及对应文件数
+func TagCountsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "tags": catalog.TagCounts()})
+}
+
+// TagFilesHandler 获取带有指定标签的文件列表
+func TagFilesHandler(c *gin.Context) {
+	tag := c.Param("tag")
+
+	files := []models.FileInfo{}
+	for _, info := range catalog.List() {
+		if info.HasTag(tag) {
+			files = append(files, info)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "tag": tag, "count": len(files), "files": files})
+}
+
+// UpdateTagsHandler 为一个或多个文件添加/移除标签
+func UpdateTagsHandler(c *gin.Context) {
+	var request struct {
+		Paths  []string `json:"paths" binding:"required"`
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if len(request.Paths) == 0 || len(request.Add)+len(request.Remove) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请指定文件以及要添加或移除的标签"})
+		return
+	}
+
+	updated, failed := service.UpdateFileTags(request.Paths, request.Add, request.Remove)
+	c.JSON(http.StatusOK, gin.H{
+		"success": len(updated) > 0,
+		"updated": len(updated),
+		"files":   updated,
+		"failed":  failed,
+	})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/rules"
+	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+)
+
+// requestFormat 根据 format 参数或 Content-Type 判断文档格式，无法判断时返回空（自动识别）
+func requestFormat(c *gin.Context) string {
+	switch strings.ToLower(c.Query("format")) {
+	case "yaml", "yml":
+		return "yaml"
+	case "json":
+		return "json"
+	}
+	contentType := c.ContentType()
+	switch {
+	case strings.Contains(contentType, "yaml"):
+		return "yaml"
+	case strings.Contains(contentType, "json"):
+		return "json"
+	}
+	return ""
+}
+
+// ExportTaxonomyHandler 导出当前分类体系和规则，?format=yaml 导出 YAML
+func ExportTaxonomyHandler(c *gin.Context) {
+	format := requestFormat(c)
+	if format == "" {
+		format = "json"
+	}
+	data, err := taxonomy.Export().Marshal(format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "导出分类体系失败: " + err.Error()})
+		return
+	}
+
+	contentType, ext := "application/json; charset=utf-8", "json"
+	if format == "yaml" {
+		contentType, ext = "application/yaml; charset=utf-8", "yaml"
+	}
+	c.Header("Content-Disposition", "attachment; filename=taxonomy."+ext)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ImportTaxonomyHandler 导入分类体系文档（JSON 或 YAML）
+// ?dryRun=true 时只返回差异：新增/删除/修改的分类及会改变分类的文件，不做任何修改
+func ImportTaxonomyHandler(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "读取请求失败: " + err.Error()})
+		return
+	}
+	doc, err := taxonomy.ParseDocument(data, requestFormat(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	applyTaxonomyDocument(c, doc, "import")
+}
+
+// WhatIfHandler 试运行候选分类体系或规则集：返回每个文件的当前分类与候选分类及汇总的移动数
+// 请求体格式同导入文档，省略 categories 时沿用当前分类体系，省略 rules 时沿用当前规则；不做任何修改
+func WhatIfHandler(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "读取请求失败: " + err.Error()})
+		return
+	}
+	doc, err := taxonomy.ParseDocument(data, requestFormat(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if doc.Categories == nil {
+		current := taxonomy.Current()
+		doc.Categories = current.Clone().Categories
+		doc.Policy = current.Policy
+	}
+
+	report, err := service.WhatIf(doc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if changedOnly, _ := strconv.ParseBool(c.Query("changedOnly")); changedOnly {
+		report.Results = report.Changes
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "report": report})
+}
+
+// TaxonomyHistoryHandler 列出分类体系的历史版本
+func TaxonomyHistoryHandler(c *gin.Context) {
+	history, err := taxonomy.History()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "current": taxonomy.Current().Version, "versions": history})
+}
+
+// RollbackTaxonomyHandler 回滚到指定的历史版本（作为新版本生效），支持 ?dryRun=true
+func RollbackTaxonomyHandler(c *gin.Context) {
+	var request struct {
+		Version int `json:"version" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	doc, err := taxonomy.LoadVersion(request.Version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	applyTaxonomyDocument(c, doc, "rollback")
+}
+
+// applyTaxonomyDocument 试运行或应用分类体系文档并写入响应
+func applyTaxonomyDocument(c *gin.Context, doc *taxonomy.Document, source string) {
+	diff, err := service.PreviewDocument(doc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if dryRun, _ := strconv.ParseBool(c.Query("dryRun")); dryRun {
+		c.JSON(http.StatusOK, gin.H{"success": true, "dryRun": true, "diff": diff})
+		return
+	}
+
+	version, err := taxonomy.Apply(doc, source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "version": version, "diff": diff})
+}
+
+// ConfigStatusHandler 返回当前生效的分类体系版本、生效时间及配置文件的监视状态
+func ConfigStatusHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"taxonomy": taxonomy.CurrentStatus(),
+		"rules":    len(rules.List()),
+		"watch":    service.GetWatchStatus(),
+	})
+}
@@ -23,10 +23,9 @@ func UploadHandler(c *gin.Context) {
 
 	files := form.File["files"]
 	service.CheckFiles(c, files)
-	// 重置统计
-	service.ResetClassificationStats()
+	// 新上传的文件追加到文件目录，不再重置已有分类
 	log.Printf("开始处理 %d 个文件", len(files))
-	service.ClassificDOC(c, files)
+	service.ClassificDOC(c, files, currentUsername(c))
 	// 更新结果中的分类统计
 
 }
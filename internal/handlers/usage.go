@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"file-classifier/internal/usage"
+	"file-classifier/internal/utils"
+)
+
+// usageFilter 从查询参数读取用量的筛选条件，from、to 为 YYYY-MM-DD 格式的日期（均包含在内）
+func usageFilter(c *gin.Context) (usage.Filter, error) {
+	f := usage.Filter{
+		Path:  c.Query("path"),
+		Batch: c.Query("batch"),
+		User:  c.Query("user"),
+		Model: c.Query("model"),
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("from 日期格式错误，应为 YYYY-MM-DD")
+		}
+		f.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("to 日期格式错误，应为 YYYY-MM-DD")
+		}
+		f.To = t.AddDate(0, 0, 1)
+	}
+	return f, nil
+}
+
+// UsageSummaryHandler 汇总 AI 用量和费用
+// period 为 day（默认）或 month 时按日、按月汇总；groupBy 为 model、user、batch 或 file 时按该维度汇总
+func UsageSummaryHandler(c *gin.Context) {
+	f, err := usageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	by := c.DefaultQuery("period", usage.PeriodDay)
+	if groupBy := c.Query("groupBy"); groupBy != "" {
+		by = groupBy
+	}
+	totals, err := usage.Summarize(f, by)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"by":       by,
+		"currency": usage.CurrentPricing().Currency,
+		"totals":   totals,
+		"total":    usage.Total(f),
+	})
+}
+
+// UsageRecordsHandler 列出 AI 用量明细，按时间从新到旧，limit 默认 100
+func UsageRecordsHandler(c *gin.Context) {
+	f, err := usageFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "limit 必须为非负整数"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "records": usage.Query(f, limit)})
+}
+
+// UsageBudgetHandler 返回今日、本月的用量和预算，以及是否已跳过 AI 分类
+func UsageBudgetHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "budget": usage.GetBudgetStatus()})
+}
+
+// GetPricingHandler 返回模型价格和预算配置
+func GetPricingHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "pricing": usage.CurrentPricing()})
+}
+
+// UpdatePricingHandler 替换模型价格和预算配置并保存到配置文件，新价格只影响之后的记录
+func UpdatePricingHandler(c *gin.Context) {
+	var pricing usage.Pricing
+	if err := c.ShouldBindJSON(&pricing); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "请求参数错误: " + err.Error()})
+		return
+	}
+	if err := usage.SetPricing(pricing); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err := utils.WriteJSONFile(utils.GetPricingConfigPath(), usage.CurrentPricing()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "保存价格配置失败: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "pricing": usage.CurrentPricing(), "budget": usage.GetBudgetStatus()})
+}
@@ -1,30 +1,126 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // FileInfo 文件信息结构
 type FileInfo struct {
 	Name     string    `json:"name"`
 	Path     string    `json:"path"`
 	Size     int64     `json:"size"`
-	Type     string    `json:"type"`     // "filename", "ai", "failed"
+	Type     string    `json:"type"`     // "filename", "ai", "content", "manual", "failed"
 	Category string    `json:"category"` // 文件分类
 	ModTime  time.Time `json:"modTime"`  // 修改时间
+
+	Confidence float64  `json:"confidence"`        // 分类置信度 0~1
+	Matches    []string `json:"matches,omitempty"` // 命中的关键词或规则名
+	Reason     string   `json:"reason,omitempty"`  // 分类依据说明（含AI给出的原因）
+
+	Override *Override `json:"override,omitempty"` // 用户手动指定的分类，重新扫描时保留
+
+	Tags     []string `json:"tags,omitempty"`     // 用户添加的标签，重新扫描时保留
+	RuleTags []string `json:"ruleTags,omitempty"` // 规则生成的标签，每次分类时重新计算
+
+	CreatedAt time.Time `json:"createdAt"` // 首次入库时间
+	UpdatedAt time.Time `json:"updatedAt"` // 最近一次分类时间
+}
+
+// AllTags 返回用户标签和规则标签的并集（已排序、去重）
+func (f FileInfo) AllTags() []string {
+	return NormalizeTags(append(append([]string{}, f.Tags...), f.RuleTags...))
+}
+
+// HasTag 判断文件是否带有指定标签
+func (f FileInfo) HasTag(tag string) bool {
+	for _, t := range f.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	for _, t := range f.RuleTags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags 去除首尾空白、空标签和重复标签，并按名称排序
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Override 用户手动调整分类的记录
+type Override struct {
+	Category         string    `json:"category"`
+	PreviousCategory string    `json:"previousCategory"`
+	User             string    `json:"user"`
+	Time             time.Time `json:"time"`
 }
 
 // CategoryStats 分类统计结构
+// 有子分类时 Count、Files、Tags 均包含所有子分类的文件
 type CategoryStats struct {
-	Count int        `json:"count"`
-	Files []FileInfo `json:"files"`
+	Count    int                      `json:"count"`
+	Files    []FileInfo               `json:"files"`
+	Tags     map[string]int           `json:"tags,omitempty"` // 该分类下各标签的文件数
+	Children map[string]CategoryStats `json:"children,omitempty"`
+
+	// 分类的显示属性
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Order       int    `json:"order,omitempty"`
 }
 
 // UploadResult 上传结果结构
 type UploadResult struct {
 	Total               int                      `json:"total"`
 	Processed           int                      `json:"processed"`
-	FirstStepClassified int                      `json:"firstStepClassified"`
-	AIClassified        int                      `json:"aiClassified"`
+	FirstStepClassified int                      `json:"firstStepClassified"` // 由文件名关键词分类的文件数
+	AIClassified        int                      `json:"aiClassified"`        // 由 AI 分类的文件数
+	ByClassifier        map[string]int           `json:"byClassifier"`        // 各分类器（含 manual、failed）给出结果的文件数
 	Classifications     map[string]CategoryStats `json:"classifications"`
+	BatchID             string                   `json:"batchId,omitempty"` // 本次上传或扫描的批次，用于查询 AI 用量
+	Usage               *UsageTotals             `json:"usage,omitempty"`   // 本批次的 AI 用量
+}
+
+// Count 按分类结果的类型（FileInfo.Type）计数
+func (r *UploadResult) Count(fileType string) {
+	if r.ByClassifier == nil {
+		r.ByClassifier = make(map[string]int)
+	}
+	r.ByClassifier[fileType]++
+	switch fileType {
+	case "filename":
+		r.FirstStepClassified++
+	case "ai":
+		r.AIClassified++
+	}
+	r.Processed++
+}
+
+// UsageTotals 汇总的 AI 用量
+type UsageTotals struct {
+	Key              string  `json:"key"` // 日期、月份或分组值
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	TotalTokens      int     `json:"totalTokens"`
+	Cost             float64 `json:"cost"`
 }
 
 // Response 响应结构
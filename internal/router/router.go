@@ -31,6 +31,43 @@ func SetupRouter() *gin.Engine {
 		api.POST("/scan-uploads", handlers.ScanUploadsHandler)
 		api.POST("/add-category", handlers.AddCategoryHandler)
 		api.GET("/categories", handlers.GetCategoriesHandler)
+		api.PUT("/categories/policy", handlers.UpdateCategoryPolicyHandler)
+		api.PUT("/categories/order", handlers.ReorderCategoriesHandler)
+		api.PUT("/categories/:name", handlers.UpdateCategoryHandler)
+		api.POST("/categories/:name/rename", handlers.RenameCategoryHandler)
+		api.POST("/delete-category", handlers.DeleteCategoryHandler)
+		api.GET("/taxonomy/export", handlers.ExportTaxonomyHandler)
+		api.POST("/taxonomy/import", handlers.ImportTaxonomyHandler)
+		api.GET("/taxonomy/history", handlers.TaxonomyHistoryHandler)
+		api.POST("/taxonomy/rollback", handlers.RollbackTaxonomyHandler)
+		api.POST("/whatif", handlers.WhatIfHandler)
+		api.GET("/config/status", handlers.ConfigStatusHandler)
+		api.POST("/evaluate", handlers.EvaluateHandler)
+		api.GET("/ai/status", handlers.AIStatusHandler)
+		api.POST("/ai/reset", handlers.ResetAIBreakersHandler)
+		api.GET("/cache/stats", handlers.CacheStatsHandler)
+		api.POST("/cache/invalidate", handlers.InvalidateCacheHandler)
+		api.GET("/usage/summary", handlers.UsageSummaryHandler)
+		api.GET("/usage/records", handlers.UsageRecordsHandler)
+		api.GET("/usage/budget", handlers.UsageBudgetHandler)
+		api.GET("/usage/pricing", handlers.GetPricingHandler)
+		api.PUT("/usage/pricing", handlers.UpdatePricingHandler)
+		api.POST("/reclassify", handlers.ReclassifyHandler)
+		api.GET("/overrides", handlers.OverrideHistoryHandler)
+		api.GET("/model", handlers.ModelInfoHandler)
+		api.POST("/model/train", handlers.TrainModelHandler)
+
+		// 标签
+		api.GET("/tags", handlers.TagCountsHandler)
+		api.GET("/tags/:tag", handlers.TagFilesHandler)
+		api.POST("/tags", handlers.UpdateTagsHandler)
+
+		// 分类规则
+		api.GET("/rules", handlers.ListRulesHandler)
+		api.POST("/rules", handlers.CreateRuleHandler)
+		api.GET("/rules/:id", handlers.GetRuleHandler)
+		api.PUT("/rules/:id", handlers.UpdateRuleHandler)
+		api.DELETE("/rules/:id", handlers.DeleteRuleHandler)
 
 		// 鉴权相关
 		auth := api.Group("/auth")
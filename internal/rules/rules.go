@@ -0,0 +1,416 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"file-classifier/internal/models"
+	"file-classifier/internal/utils"
+)
+
+// 声明式分类规则：按文件名正则、扩展名、大小范围、上传目录、
+// 文本正则和文档元数据组合条件，命中时直接归入指定分类。
+// 规则按优先级从高到低依次匹配，第一个命中的规则决定分类；
+// 所有命中规则的标签都会附加到文件上，未指定分类的规则只用于打标签。
+
+// Conditions 规则条件，所有非空条件需同时满足
+type Conditions struct {
+	FilenameRegex string            `json:"filenameRegex,omitempty"`
+	Extensions    []string          `json:"extensions,omitempty"` // 如 [".xlsx", ".xls"]
+	MinSize       int64             `json:"minSize,omitempty"`    // 字节，0 表示不限
+	MaxSize       int64             `json:"maxSize,omitempty"`    // 字节，0 表示不限
+	PathPrefix    string            `json:"pathPrefix,omitempty"` // 相对 uploads 的目录前缀，如 "财务/2024"
+	TextRegex     string            `json:"textRegex,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"` // 元数据键 -> 正则，如 {"author": "财务部"}
+}
+
+// Rule 分类规则
+type Rule struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Category   string     `json:"category,omitempty"` // 为空时仅打标签
+	Tags       []string   `json:"tags,omitempty"`
+	Priority   int        `json:"priority"` // 越大越先匹配
+	Enabled    bool       `json:"enabled"`
+	Conditions Conditions `json:"conditions"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// Facts 规则匹配所需的文件信息，文本和元数据按需提取
+type Facts struct {
+	Filename string
+	RelPath  string // 相对 uploads 的路径
+	Size     int64
+	Text     func() (string, error)
+	Metadata func() (map[string]string, error)
+}
+
+// compiled 预编译的规则
+type compiled struct {
+	rule     Rule
+	filename *regexp.Regexp
+	text     *regexp.Regexp
+	metadata map[string]*regexp.Regexp
+}
+
+// Set 一组预编译的规则，创建后不再修改
+type Set struct {
+	list []compiled
+}
+
+var (
+	current   atomic.Pointer[Set]
+	rulesLock sync.Mutex // 串行化修改，读取无需加锁
+	storePath string
+)
+
+func init() {
+	current.Store(&Set{})
+}
+
+// ErrRuleNotFound 规则不存在
+var ErrRuleNotFound = errors.New("规则不存在")
+
+// Compile 校验并编译一组规则；未指定ID的规则会分配新ID
+func Compile(list []Rule) (*Set, error) {
+	set := &Set{list: make([]compiled, 0, len(list))}
+	for _, r := range list {
+		if r.ID == "" {
+			r.ID = uuid.NewString()
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %q 无效: %v", r.Name, err)
+		}
+		set.list = append(set.list, c)
+	}
+	sortRules(set.list)
+	return set, nil
+}
+
+// Init 从文件加载规则；文件不存在时为空规则集
+func Init(path string) error {
+	var loaded []Rule
+	if err := utils.ReadJSONFile(path, &loaded); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	set, err := Compile(loaded)
+	if err != nil {
+		return err
+	}
+
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	current.Store(set)
+	storePath = path
+	return nil
+}
+
+// Current 返回当前生效的规则集快照，替换后已取得旧快照的调用方不受影响
+func Current() *Set {
+	return current.Load()
+}
+
+// Replace 用一组新规则整体替换当前规则集并持久化
+func Replace(list []Rule) error {
+	set, err := Compile(list)
+	if err != nil {
+		return err
+	}
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	return save(set.list)
+}
+
+// List 返回当前所有规则，按优先级从高到低排列
+func List() []Rule {
+	return Current().List()
+}
+
+// List 返回规则集中的所有规则，按优先级从高到低排列
+func (s *Set) List() []Rule {
+	result := make([]Rule, 0, len(s.list))
+	for _, c := range s.list {
+		result = append(result, c.rule)
+	}
+	return result
+}
+
+// Get 按ID获取规则
+func Get(id string) (Rule, error) {
+	for _, c := range Current().list {
+		if c.rule.ID == id {
+			return c.rule, nil
+		}
+	}
+	return Rule{}, ErrRuleNotFound
+}
+
+// Validate 校验规则字段并编译正则
+func Validate(r Rule) error {
+	_, err := compile(r)
+	return err
+}
+
+// Create 新增规则
+func Create(r Rule) (Rule, error) {
+	now := time.Now()
+	r.ID = uuid.NewString()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	c, err := compile(r)
+	if err != nil {
+		return r, err
+	}
+
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	list := append(append([]compiled{}, Current().list...), c)
+	if err := save(list); err != nil {
+		return r, err
+	}
+	return c.rule, nil
+}
+
+// Update 更新规则，保留ID和创建时间
+func Update(id string, r Rule) (Rule, error) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+
+	for i, old := range Current().list {
+		if old.rule.ID != id {
+			continue
+		}
+		r.ID = id
+		r.CreatedAt = old.rule.CreatedAt
+		r.UpdatedAt = time.Now()
+		c, err := compile(r)
+		if err != nil {
+			return r, err
+		}
+		list := append([]compiled{}, Current().list...)
+		list[i] = c
+		if err := save(list); err != nil {
+			return r, err
+		}
+		return c.rule, nil
+	}
+	return r, ErrRuleNotFound
+}
+
+// Delete 删除规则
+func Delete(id string) error {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+
+	for i, old := range Current().list {
+		if old.rule.ID != id {
+			continue
+		}
+		list := append(append([]compiled{}, Current().list[:i]...), Current().list[i+1:]...)
+		return save(list)
+	}
+	return ErrRuleNotFound
+}
+
+// ReassignCategory 将指向分类 from 的规则改为指向 to；to 为 "未分类" 时，
+// 带标签的规则只清除分类、继续打标签，不带标签的规则停用。返回受影响的规则数
+func ReassignCategory(from, to string) (int, error) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+
+	list := append([]compiled{}, Current().list...)
+	changed := 0
+	for i, c := range list {
+		if c.rule.Category != from {
+			continue
+		}
+		switch {
+		case to == "未分类" && len(c.rule.Tags) > 0:
+			c.rule.Category = ""
+		case to == "未分类":
+			c.rule.Enabled = false
+		default:
+			c.rule.Category = to
+		}
+		c.rule.UpdatedAt = time.Now()
+		list[i] = c
+		changed++
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, save(list)
+}
+
+// Match 使用当前规则集匹配，见 (*Set).Match
+func Match(f Facts) (Rule, bool) {
+	return Current().Match(f)
+}
+
+// MatchTags 使用当前规则集匹配标签，见 (*Set).MatchTags
+func MatchTags(f Facts) []string {
+	return Current().MatchTags(f)
+}
+
+// Match 返回第一个命中的、指定了分类的已启用规则
+func (s *Set) Match(f Facts) (Rule, bool) {
+	for _, c := range s.list {
+		if c.rule.Enabled && c.rule.Category != "" && c.matches(f) {
+			return c.rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// MatchTags 返回所有命中的已启用规则的标签
+func (s *Set) MatchTags(f Facts) []string {
+	var tags []string
+	for _, c := range s.list {
+		if c.rule.Enabled && len(c.rule.Tags) > 0 && c.matches(f) {
+			tags = append(tags, c.rule.Tags...)
+		}
+	}
+	return models.NormalizeTags(tags)
+}
+
+// save 持久化并替换当前规则集，调用方需持有写锁
+func save(list []compiled) error {
+	sortRules(list)
+	out := make([]Rule, 0, len(list))
+	for _, c := range list {
+		out = append(out, c.rule)
+	}
+	if storePath != "" {
+		if err := utils.WriteJSONFile(storePath, out); err != nil {
+			return fmt.Errorf("保存规则失败: %v", err)
+		}
+	}
+	current.Store(&Set{list: list})
+	return nil
+}
+
+func sortRules(list []compiled) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].rule.Priority != list[j].rule.Priority {
+			return list[i].rule.Priority > list[j].rule.Priority
+		}
+		return list[i].rule.CreatedAt.Before(list[j].rule.CreatedAt)
+	})
+}
+
+func compile(r Rule) (compiled, error) {
+	c := compiled{rule: r}
+	if strings.TrimSpace(r.Name) == "" {
+		return c, fmt.Errorf("规则名称不能为空")
+	}
+	c.rule.Tags = models.NormalizeTags(r.Tags)
+	if strings.TrimSpace(r.Category) == "" && len(c.rule.Tags) == 0 {
+		return c, fmt.Errorf("规则需指定分类或标签")
+	}
+	cond := r.Conditions
+	if cond.MinSize < 0 || cond.MaxSize < 0 || (cond.MaxSize > 0 && cond.MinSize > cond.MaxSize) {
+		return c, fmt.Errorf("文件大小范围无效")
+	}
+
+	var err error
+	if cond.FilenameRegex != "" {
+		if c.filename, err = regexp.Compile(cond.FilenameRegex); err != nil {
+			return c, fmt.Errorf("文件名正则无效: %v", err)
+		}
+	}
+	if cond.TextRegex != "" {
+		if c.text, err = regexp.Compile(cond.TextRegex); err != nil {
+			return c, fmt.Errorf("文本正则无效: %v", err)
+		}
+	}
+	if len(cond.Metadata) > 0 {
+		c.metadata = make(map[string]*regexp.Regexp)
+		for key, pattern := range cond.Metadata {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return c, fmt.Errorf("元数据 %s 正则无效: %v", key, err)
+			}
+			c.metadata[strings.ToLower(key)] = re
+		}
+	}
+	c.rule.Conditions.Extensions = append([]string(nil), cond.Extensions...)
+	for i, ext := range c.rule.Conditions.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		c.rule.Conditions.Extensions[i] = ext
+	}
+	return c, nil
+}
+
+// matches 判断文件是否满足规则的全部条件；开销较大的文本和元数据条件最后判断
+func (c compiled) matches(f Facts) bool {
+	cond := c.rule.Conditions
+
+	if len(cond.Extensions) > 0 {
+		ext := strings.ToLower(path.Ext(f.Filename))
+		found := false
+		for _, e := range cond.Extensions {
+			if e == ext {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if cond.MinSize > 0 && f.Size < cond.MinSize {
+		return false
+	}
+	if cond.MaxSize > 0 && f.Size > cond.MaxSize {
+		return false
+	}
+	if cond.PathPrefix != "" {
+		dir := path.Dir(strings.ReplaceAll(f.RelPath, "\\", "/"))
+		prefix := strings.Trim(strings.ReplaceAll(cond.PathPrefix, "\\", "/"), "/")
+		if dir != prefix && !strings.HasPrefix(dir, prefix+"/") {
+			return false
+		}
+	}
+	if c.filename != nil && !c.filename.MatchString(f.Filename) {
+		return false
+	}
+	if c.text != nil {
+		if f.Text == nil {
+			return false
+		}
+		text, err := f.Text()
+		if err != nil || !c.text.MatchString(text) {
+			return false
+		}
+	}
+	if len(c.metadata) > 0 {
+		if f.Metadata == nil {
+			return false
+		}
+		meta, err := f.Metadata()
+		if err != nil {
+			return false
+		}
+		for key, re := range c.metadata {
+			if !re.MatchString(meta[key]) {
+				return false
+			}
+		}
+	}
+	return true
+}
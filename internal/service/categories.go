@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/taxonomy"
+)
+
+// CategoryChange 删除或重命名分类时受影响的文件和规则数
+type CategoryChange struct {
+	Files int `json:"files"`
+	Rules int `json:"rules"`
+}
+
+// DeleteCategory 删除分类，并将其文件（含手动归入的文件）移到 reassignTo
+// reassignTo 为空时移到未分类；指向该分类的规则改为指向 reassignTo，移到未分类时停用
+func DeleteCategory(name, reassignTo, user string) (CategoryChange, error) {
+	var change CategoryChange
+	if reassignTo == "" {
+		reassignTo = taxonomy.Unclassified
+	}
+	if reassignTo == name {
+		return change, fmt.Errorf("不能将文件移到被删除的分类")
+	}
+	if !taxonomy.Current().Has(reassignTo) {
+		return change, fmt.Errorf("目标分类不存在: %s", reassignTo)
+	}
+
+	if err := taxonomy.DeleteCategory(name); err != nil {
+		return change, err
+	}
+
+	var err error
+	reason := fmt.Sprintf("分类「%s」已被 %s 删除，归入「%s」", name, user, reassignTo)
+	if change.Files, err = catalog.Recategorize(name, reassignTo, reason); err != nil {
+		return change, fmt.Errorf("迁移文件记录失败: %v", err)
+	}
+	if change.Rules, err = rules.ReassignCategory(name, reassignTo); err != nil {
+		return change, fmt.Errorf("更新分类规则失败: %v", err)
+	}
+	if change.Rules > 0 {
+		taxonomy.SnapshotRules()
+	}
+	log.Printf("删除分类: %s -> %s，迁移 %d 个文件、%d 条规则 (%s)", name, reassignTo, change.Files, change.Rules, user)
+	return change, nil
+}
+
+// RenameCategory 重命名分类，并迁移已有的文件记录和规则
+func RenameCategory(oldName, newName, user string) (CategoryChange, error) {
+	var change CategoryChange
+	if err := taxonomy.RenameCategory(oldName, newName); err != nil {
+		return change, err
+	}
+
+	var err error
+	if change.Files, err = catalog.Recategorize(oldName, newName, ""); err != nil {
+		return change, fmt.Errorf("迁移文件记录失败: %v", err)
+	}
+	if change.Rules, err = rules.ReassignCategory(oldName, newName); err != nil {
+		return change, fmt.Errorf("更新分类规则失败: %v", err)
+	}
+	if change.Rules > 0 {
+		taxonomy.SnapshotRules()
+	}
+	log.Printf("重命名分类: %s -> %s，迁移 %d 个文件、%d 条规则 (%s)", oldName, newName, change.Files, change.Rules, user)
+	return change, nil
+}
@@ -2,61 +2,188 @@ package service
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"log"
-	"math/rand"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 
+	"file-classifier/internal/catalog"
 	"file-classifier/internal/config"
 	"file-classifier/internal/models"
+	"file-classifier/internal/taxonomy"
+	"file-classifier/internal/usage"
 )
 
 // ClassifyByFilename 根据文件名进行分类
 func ClassifyByFilename(filename string) string {
-	lowerName := strings.ToLower(filename)
+	return ScoreKeywords(filename, "").Category
+}
 
-	for category, keywords := range config.ClassificationKeywords {
-		for _, keyword := range keywords {
-			if strings.Contains(lowerName, strings.ToLower(keyword)) {
-				return category
-			}
+// ClassifyFile 使用当前流水线对磁盘上的文件分类，并将结果写入 fileInfo
+// fullPath 为磁盘路径，fileInfo.Name 作为文件名参与分类
+// 用户手动指定过分类的文件直接沿用手动分类，不再运行流水线；规则标签总是重新计算
+// account 为 AI 用量的归属
+func ClassifyFile(fullPath string, fileInfo *models.FileInfo, account usage.Account) Result {
+	in := NewInput(fullPath, fileInfo.Name, fileInfo.Size)
+	in.RelPath = fileInfo.Path
+	in.Account = account
+	fileInfo.RuleTags = in.Rules.MatchTags(ruleFacts(in))
+
+	if applyUserState(fileInfo) {
+		return Result{Category: fileInfo.Category, Confidence: 1, Reason: fileInfo.Reason, Classifier: "manual"}
+	}
+
+	result := CurrentPipeline().Classify(in)
+	fileInfo.Category = result.Category
+	fileInfo.Type = result.Classifier
+	fileInfo.Confidence = result.Confidence
+	fileInfo.Matches = result.Matches
+	fileInfo.Reason = result.Reason
+	return result
+}
+
+// AddFileToCategory 将文件分类结果写入文件目录
+// 若该文件已有用户手动分类或用户标签，则予以保留
+func AddFileToCategory(category string, fileInfo models.FileInfo) {
+	fileInfo.Category = category
+	applyUserState(&fileInfo)
+	if _, err := catalog.Put(fileInfo); err != nil {
+		log.Printf("保存分类结果失败: %s, %v", fileInfo.Path, err)
+	}
+}
+
+// applyUserState 将目录中已有的用户标签和手动分类写入 fileInfo
+// 存在手动分类时返回 true
+func applyUserState(fileInfo *models.FileInfo) bool {
+	existing, ok := catalog.Get(fileInfo.Path)
+	if !ok {
+		return false
+	}
+	fileInfo.Tags = existing.Tags
+	if existing.Override == nil {
+		return false
+	}
+	fileInfo.Category = existing.Override.Category
+	fileInfo.Type = "manual"
+	fileInfo.Confidence = 1
+	fileInfo.Matches = nil
+	fileInfo.Reason = existing.Reason
+	fileInfo.Override = existing.Override
+	return true
+}
+
+// UpdateFileTags 为多个文件添加/移除标签，返回更新后的记录及失败原因
+func UpdateFileTags(paths, add, remove []string) ([]models.FileInfo, map[string]string) {
+	updated := []models.FileInfo{}
+	failed := make(map[string]string)
+	for _, path := range paths {
+		info, err := catalog.UpdateTags(path, add, remove)
+		if err != nil {
+			failed[path] = err.Error()
+			continue
 		}
+		updated = append(updated, info)
 	}
-	return "未分类"
+	return updated, failed
 }
 
-// ClassifyByAI AI分析占位符函数
-func ClassifyByAI(filename string) string {
+// ReclassifyFiles 将多个文件手动归入指定分类，返回更新后的记录及失败原因
+func ReclassifyFiles(paths []string, category, user string) ([]models.FileInfo, map[string]string) {
+	updated := []models.FileInfo{}
+	failed := make(map[string]string)
+	for _, path := range paths {
+		info, err := catalog.Reassign(path, category, user)
+		if err != nil {
+			failed[path] = err.Error()
+			continue
+		}
+		log.Printf("手动分类: %s %s -> %s (%s)", path, info.Override.PreviousCategory, category, user)
+		updated = append(updated, info)
+	}
+	return updated, failed
+}
 
-	// 模拟AI分析结果（随机返回一个分类）
-	categories := []string{"合同", "简历", "发票", "论文"}
-	randomCategory := categories[rand.Intn(len(categories))]
+// GetCategoryNames 返回分类树中的所有分类名（含未分类），按名称排序
+func GetCategoryNames() []string {
+	names := append(taxonomy.Current().Names(), taxonomy.Unclassified)
+	sort.Strings(names)
+	return names
+}
 
-	log.Printf("AI分析结果: %s -> %s", filename, randomCategory)
-	return randomCategory
+// GetClassificationStats 从文件目录汇总各分类统计
+// 只返回顶级分类（及未分类、已不在分类树中的分类），子分类嵌套在 Children 中并汇总到父分类
+func GetClassificationStats() map[string]models.CategoryStats {
+	tax := taxonomy.Current()
+	flat := catalog.Stats(GetCategoryNames())
+
+	result := make(map[string]models.CategoryStats)
+	for _, root := range tax.Children("") {
+		result[root] = rollUp(tax, root, flat)
+	}
+	for name, stats := range flat {
+		if !tax.Has(name) || name == taxonomy.Unclassified {
+			result[name] = stats
+		}
+	}
+	return result
 }
 
-// ResetClassificationStats 重置分类统计
-func ResetClassificationStats() {
-	for key := range config.ClassificationStats {
-		config.ClassificationStats[key] = models.CategoryStats{Count: 0, Files: []models.FileInfo{}}
+// GetCategoryStats 返回任意层级分类的统计（含子分类）
+func GetCategoryStats(category string) (models.CategoryStats, bool) {
+	tax := taxonomy.Current()
+	flat := catalog.Stats(GetCategoryNames())
+	if _, ok := tax.Get(category); ok {
+		return rollUp(tax, category, flat), true
 	}
+	stats, ok := flat[category]
+	return stats, ok
 }
 
-// AddFileToCategory 添加文件到分类
-func AddFileToCategory(category string, fileInfo models.FileInfo) {
-	// 使用全局互斥锁保护共享数据
-	config.StatsMutex.Lock()
-	defer config.StatsMutex.Unlock()
+// InCategory 判断文件分类是否为 category 或其子分类
+func InCategory(fileCategory, category string) bool {
+	for _, name := range taxonomy.Current().Path(fileCategory) {
+		if name == category {
+			return true
+		}
+	}
+	return false
+}
+
+// rollUp 将子分类的文件和标签汇总到 name
+func rollUp(tax *taxonomy.Taxonomy, name string, flat map[string]models.CategoryStats) models.CategoryStats {
+	own := flat[name]
+	stats := models.CategoryStats{Files: append([]models.FileInfo{}, own.Files...)}
+	if cat, ok := tax.Get(name); ok {
+		stats.Description, stats.Icon, stats.Order = cat.Description, cat.Icon, cat.Order
+	}
+	addTags := func(tags map[string]int) {
+		for tag, n := range tags {
+			if stats.Tags == nil {
+				stats.Tags = make(map[string]int)
+			}
+			stats.Tags[tag] += n
+		}
+	}
+	addTags(own.Tags)
 
-	if stats, exists := config.ClassificationStats[category]; exists {
-		stats.Files = append(stats.Files, fileInfo)
-		stats.Count = len(stats.Files)
-		config.ClassificationStats[category] = stats
+	for _, child := range tax.Children(name) {
+		childStats := rollUp(tax, child, flat)
+		if stats.Children == nil {
+			stats.Children = make(map[string]models.CategoryStats)
+		}
+		stats.Children[child] = childStats
+		stats.Files = append(stats.Files, childStats.Files...)
+		addTags(childStats.Tags)
 	}
+	sort.Slice(stats.Files, func(i, j int) bool {
+		return stats.Files[i].Path < stats.Files[j].Path
+	})
+	stats.Count = len(stats.Files)
+	return stats
 }
 
 func CheckFiles(c *gin.Context, files []*multipart.FileHeader) {
@@ -76,20 +203,24 @@ func CheckFiles(c *gin.Context, files []*multipart.FileHeader) {
 	}
 
 }
-func ClassificDOC(c *gin.Context, files []*multipart.FileHeader) {
+
+// ClassificDOC 保存上传的文件并分类，本次上传作为一个批次记录 AI 用量，user 为上传用户
+func ClassificDOC(c *gin.Context, files []*multipart.FileHeader, user string) {
 	results := &models.UploadResult{
 		Total:               len(files),
 		Processed:           0,
 		FirstStepClassified: 0,
 		AIClassified:        0,
-		Classifications:     config.ClassificationStats,
+		BatchID:             uuid.NewString(),
 	}
+	account := usage.Account{Batch: results.BatchID, User: user}
 
 	// Create a channel to limit concurrent goroutines
 	maxConcurrent := 30
 	semaphore := make(chan struct{}, maxConcurrent)
 
 	var wg sync.WaitGroup
+	var mu sync.Mutex // 保护 results 计数
 
 	for _, file := range files {
 		wg.Add(1)
@@ -99,7 +230,6 @@ func ClassificDOC(c *gin.Context, files []*multipart.FileHeader) {
 			defer func() { <-semaphore }() // Release the slot
 
 			filename := file.Filename
-			category := ClassifyByFilename(filename)
 
 			// Save file
 			savePath := filepath.Join(config.UploadDir, filename)
@@ -109,32 +239,28 @@ func ClassificDOC(c *gin.Context, files []*multipart.FileHeader) {
 			}
 
 			fileInfo := models.FileInfo{
-				Name: filename,
-				Path: savePath,
-				Size: file.Size,
+				Name:    filename,
+				Path:    filename, // 目录中统一使用相对于uploads的路径
+				Size:    file.Size,
+				ModTime: time.Now(),
 			}
 
-			if category != "未分类" {
-				fileInfo.Type = "filename"
-				AddFileToCategory(category, fileInfo)
-				results.FirstStepClassified++
-			} else {
-				// Second step: AI classification
-				aiCategory := ClassifyByAI(filename)
-				fileInfo.Type = "AI"
-				AddFileToCategory(aiCategory, fileInfo)
-				results.AIClassified++
-			}
-			results.Processed++
+			ClassifyFile(savePath, &fileInfo, account)
+			AddFileToCategory(fileInfo.Category, fileInfo)
+
+			mu.Lock()
+			results.Count(fileInfo.Type)
+			mu.Unlock()
 
 		}(file)
 	}
 
 	wg.Wait()
 
-	results.Classifications = config.ClassificationStats
-	log.Printf("关键词分类完成: %d 个文件被分类", results.FirstStepClassified)
-	log.Printf("AI分析完成: %d 个文件被分类", results.AIClassified)
+	results.Classifications = GetClassificationStats()
+	batchUsage := usage.Total(usage.Filter{Batch: results.BatchID})
+	results.Usage = &batchUsage
+	log.Printf("分类完成: %d 个文件，各分类器结果 %v", results.Processed, results.ByClassifier)
 	c.JSON(http.StatusOK, models.Response{
 		Success: true,
 		Message: "文件分类完成",
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"fmt"
+
+	"file-classifier/internal/ai"
+	"file-classifier/internal/bayes"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/usage"
+	"file-classifier/internal/utils"
+)
+
+// 内置分类器：
+//   rules    - 声明式规则（扩展名、大小、目录、正则、元数据）
+//   filename - 文件名关键词匹配
+//   ai       - 提取文本后调用 AI 分类
+//   content  - 文件名和内容的加权关键词打分（确定性，常用作兜底）
+//   bayes    - 基于用户纠正样本训练的朴素贝叶斯分类器
+
+type rulesClassifier struct{}
+
+func (r *rulesClassifier) Name() string { return "rules" }
+
+func (r *rulesClassifier) Classify(in *Input) (Result, error) {
+	rule, ok := in.Rules.Match(ruleFacts(in))
+	if !ok {
+		return Result{Category: "未分类"}, nil
+	}
+	return Result{
+		Category:   rule.Category,
+		Confidence: 1,
+		Matches:    []string{rule.Name},
+		Reason:     fmt.Sprintf("命中规则「%s」（优先级 %d）", rule.Name, rule.Priority),
+	}, nil
+}
+
+// ruleFacts 将分类器输入转换为规则匹配所需的信息
+func ruleFacts(in *Input) rules.Facts {
+	return rules.Facts{
+		Filename: in.Filename,
+		RelPath:  in.RelPath,
+		Size:     in.Size,
+		Text:     in.Text,
+		Metadata: in.Metadata,
+	}
+}
+
+type filenameClassifier struct{}
+
+func (f *filenameClassifier) Name() string { return "filename" }
+
+func (f *filenameClassifier) Classify(in *Input) (Result, error) {
+	score := ScoreKeywordsIn(in.Taxonomy, in.Filename, "")
+	return keywordResult(score, "文件名"), nil
+}
+
+// aiClassifier 调用 AI 服务分类，服务由环境变量配置，可在流水线选项中覆盖
+type aiClassifier struct {
+	provider ai.Provider
+}
+
+func (a *aiClassifier) Name() string { return "ai" }
+
+func (a *aiClassifier) Classify(in *Input) (Result, error) {
+	if in.Replay != nil {
+		return replayAI(in)
+	}
+	content, err := in.Text()
+	if err != nil {
+		return Result{}, err
+	}
+	hash, _ := in.Hash()
+	account := usage.Record{Path: in.RelPath, Batch: in.Account.Batch, User: in.Account.User}
+	detail, err := ClassifyWithAIDetail(a.provider, in.Taxonomy, hash, utils.GetSafeFileName(in.Filename), content, account)
+	if err != nil {
+		return Result{}, err
+	}
+	// AI 只在顶级分类中选择，再按关键词细化到子分类
+	category := ScoreKeywordsIn(in.Taxonomy, in.Filename, content).Refine(detail.Category)
+	reason := detail.Reason
+	if category != detail.Category {
+		reason = fmt.Sprintf("%s；按关键词细化为「%s」", reason, category)
+	}
+	return Result{Category: category, Confidence: detail.Confidence, Reason: reason}, nil
+}
+
+// replayAI 沿用文件记录中保存的 AI 结果，不发起请求
+// 与实际请求一样，取原分类在 in.Taxonomy 中的顶级分类再按关键词细化；原分类已不存在时不给出结果
+func replayAI(in *Input) (Result, error) {
+	prev := in.Replay
+	if prev.Type != "ai" || !in.Taxonomy.Has(prev.Category) {
+		return Result{Category: "未分类"}, nil
+	}
+	path := in.Taxonomy.Path(prev.Category)
+	if len(path) == 0 {
+		return Result{Category: "未分类"}, nil
+	}
+	category := path[0]
+	content, _ := in.Text()
+	category = ScoreKeywordsIn(in.Taxonomy, in.Filename, content).Refine(category)
+	return Result{Category: category, Confidence: prev.Confidence, Reason: "沿用已有的 AI 分类结果"}, nil
+}
+
+type contentClassifier struct{}
+
+func (s *contentClassifier) Name() string { return "content" }
+
+func (s *contentClassifier) Classify(in *Input) (Result, error) {
+	// 提取失败时仅根据文件名判断，保证结果稳定
+	content, _ := in.Text()
+	score := ScoreKeywordsIn(in.Taxonomy, in.Filename, content)
+	return keywordResult(score, "文件名和内容"), nil
+}
+
+// keywordResult 将关键词打分结果转换为分类器输出
+func keywordResult(score KeywordScore, source string) Result {
+	if score.Category == "未分类" {
+		return Result{Category: score.Category}
+	}
+	return Result{
+		Category:   score.Category,
+		Confidence: score.Confidence,
+		Matches:    score.Matches,
+		Reason:     score.Reason(source),
+		Ambiguous:  score.Ambiguous,
+	}
+}
+
+type bayesClassifier struct{}
+
+func (b *bayesClassifier) Name() string { return "bayes" }
+
+func (b *bayesClassifier) Classify(in *Input) (Result, error) {
+	model := bayes.Current()
+	if model == nil {
+		return Result{Category: "未分类"}, nil
+	}
+
+	text := utils.GetSafeFileName(in.Filename)
+	if content, err := in.Text(); err == nil {
+		text += "\n" + content
+	}
+	prediction := model.Predict(text)
+	if len(prediction.Evidence) == 0 {
+		// 没有支持预测分类的特征，不给出结果
+		return Result{Category: "未分类"}, nil
+	}
+	return Result{
+		Category:   prediction.Category,
+		Confidence: prediction.Probability,
+		Matches:    prediction.Evidence,
+		Reason:     fmt.Sprintf("本地模型预测（%d 个训练样本）", model.Docs),
+	}, nil
+}
+
+func init() {
+	RegisterClassifier("rules", func(map[string]string) (Classifier, error) {
+		return &rulesClassifier{}, nil
+	})
+	RegisterClassifier("filename", func(map[string]string) (Classifier, error) {
+		return &filenameClassifier{}, nil
+	})
+	RegisterClassifier("ai", func(options map[string]string) (Classifier, error) {
+		cfg, err := ai.ConfigFromEnv().WithOptions(options)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := ai.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &aiClassifier{provider: provider}, nil
+	})
+	RegisterClassifier("content", func(map[string]string) (Classifier, error) {
+		return &contentClassifier{}, nil
+	})
+	RegisterClassifier("bayes", func(map[string]string) (Classifier, error) {
+		return &bayesClassifier{}, nil
+	})
+}
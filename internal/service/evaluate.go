@@ -0,0 +1,75 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"file-classifier/internal/evaluation"
+	"file-classifier/internal/usage"
+)
+
+// Evaluate 使用指定的流水线配置对标注语料逐个分类并计算指标
+// 只读取语料文件，不写入文件目录，也不使用手动分类
+func Evaluate(dir string, cfg PipelineConfig, name string) (*evaluation.Report, error) {
+	p, err := NewPipeline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := evaluation.LoadCorpus(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]evaluation.Outcome, len(samples))
+	semaphore := make(chan struct{}, previewConcurrency)
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			outcomes[i] = evaluateSample(p, samples[i])
+		}(i)
+	}
+	wg.Wait()
+	return evaluation.Compute(name, outcomes), nil
+}
+
+// EvaluateCompare 在同一语料上评估两个流水线配置并对比结果
+func EvaluateCompare(dir string, baseline, candidate PipelineConfig) (*evaluation.Comparison, error) {
+	a, err := Evaluate(dir, baseline, "baseline")
+	if err != nil {
+		return nil, fmt.Errorf("评估基线配置失败: %v", err)
+	}
+	b, err := Evaluate(dir, candidate, "candidate")
+	if err != nil {
+		return nil, fmt.Errorf("评估候选配置失败: %v", err)
+	}
+	return evaluation.Compare(a, b), nil
+}
+
+// evaluateSample 对单个样本分类
+// RelPath 只取文件名：分类子目录即标注结果，不能让按目录匹配的规则看到它
+func evaluateSample(p *Pipeline, sample evaluation.Sample) evaluation.Outcome {
+	filename := filepath.Base(sample.Path)
+	var size int64
+	if info, err := os.Stat(sample.Path); err == nil {
+		size = info.Size()
+	}
+	in := NewInput(sample.Path, filename, size)
+	in.RelPath = filename
+	in.Account = usage.Account{Batch: "evaluate"}
+
+	result := p.Classify(in)
+	return evaluation.Outcome{
+		Path:       sample.RelPath,
+		Expected:   sample.Expected,
+		Predicted:  result.Category,
+		Classifier: result.Classifier,
+		Confidence: result.Confidence,
+		Reason:     result.Reason,
+	}
+}
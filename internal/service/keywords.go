@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"file-classifier/internal/taxonomy"
+)
+
+// 关键词打分参数
+const (
+	filenameWeight    = 2.0 // 文件名命中的权重倍数
+	contentWeight     = 1.0 // 内容中每次命中的权重倍数
+	maxContentHits    = 3   // 同一关键词在内容中最多计分次数
+	ambiguityRatio    = 0.8 // 次高分达到最高分的该比例即视为有歧义
+	confidentScore    = 6.0 // 达到该分数（如主关键词出现在文件名中）才给出满置信度
+	keywordReasonSize = 5   // 说明中最多列出的关键词数
+)
+
+// KeywordScore 关键词打分结果
+type KeywordScore struct {
+	Category   string             `json:"category"` // 沿分类树下探得到的最深分类
+	Path       []string           `json:"path,omitempty"`
+	Score      float64            `json:"score"`
+	Confidence float64            `json:"confidence"` // 各层最高分占比之积 × 分数强度
+	Matches    []string           `json:"matches"`
+	Ambiguous  bool               `json:"ambiguous"` // 某一层与次高分分类得分接近或持平
+	RunnerUp   string             `json:"runnerUp,omitempty"`
+	Scores     map[string]float64 `json:"scores"` // 各分类的子树得分
+
+	tax *taxonomy.Taxonomy
+}
+
+// ScoreKeywords 对文件名和内容按加权关键词打分，沿分类树逐层下探
+// 每个分类的子树得分 = 自身关键词得分 + 得分最高的子分类的子树得分；
+// 先在顶级分类中选出最高分，再在其子分类中继续选择，直到没有子分类得分。
+// 同层分类按名称排序后依次比较，结果与 map 遍历顺序无关；平局时取名称较小者并标记为有歧义
+func ScoreKeywords(filename, content string) KeywordScore {
+	return ScoreKeywordsIn(taxonomy.Current(), filename, content)
+}
+
+// ScoreKeywordsIn 使用指定的分类体系打分，见 ScoreKeywords
+func ScoreKeywordsIn(tax *taxonomy.Taxonomy, filename, content string) KeywordScore {
+	lowerName := strings.ToLower(filename)
+	lowerContent := strings.ToLower(content)
+
+	own := make(map[string]float64)
+	matches := make(map[string][]string)
+	for _, cat := range tax.Categories {
+		own[cat.Name], matches[cat.Name] = scoreRules(cat.Keywords, lowerName, lowerContent)
+	}
+
+	result := KeywordScore{Category: taxonomy.Unclassified, Scores: make(map[string]float64), tax: tax}
+	var subtree func(name string) float64
+	subtree = func(name string) float64 {
+		best := 0.0
+		for _, child := range tax.Children(name) {
+			best = math.Max(best, subtree(child))
+		}
+		score := own[name] + best
+		if score > 0 {
+			result.Scores[name] = score
+		}
+		return score
+	}
+	for _, root := range tax.Children("") {
+		subtree(root)
+	}
+
+	confidence := 1.0
+	for level := tax.Children(""); len(level) > 0; {
+		best, runnerUp, share, ambiguous := result.pick(level)
+		if best == "" {
+			break
+		}
+		if len(result.Path) == 0 {
+			result.Score = result.Scores[best]
+		}
+		if ambiguous && !result.Ambiguous {
+			result.Ambiguous, result.RunnerUp = true, runnerUp
+		} else if result.RunnerUp == "" {
+			result.RunnerUp = runnerUp
+		}
+		confidence *= share
+		result.Category = best
+		result.Path = append(result.Path, best)
+		result.Matches = append(result.Matches, matches[best]...)
+		level = tax.Children(best)
+	}
+
+	if len(result.Path) > 0 {
+		result.Confidence = confidence * math.Min(1, result.Score/confidentScore)
+	}
+	return result
+}
+
+// Refine 从给定分类沿子树得分继续下探，返回最深的命中分类
+// 用于把其他分类器给出的顶级分类细化到子分类
+func (k KeywordScore) Refine(category string) string {
+	for {
+		best, _, _, _ := k.pick(k.tax.Children(category))
+		if best == "" {
+			return category
+		}
+		category = best
+	}
+}
+
+// pick 在同一层分类中选出子树得分最高者，返回次高者、最高分占比及是否有歧义
+func (k KeywordScore) pick(names []string) (best, runnerUp string, share float64, ambiguous bool) {
+	var top, second, total float64
+	for _, name := range names {
+		score, ok := k.Scores[name]
+		if !ok {
+			continue
+		}
+		total += score
+		if score > top {
+			second, runnerUp = top, best
+			best, top = name, score
+		} else if score > second {
+			second, runnerUp = score, name
+		}
+	}
+	if best == "" {
+		return "", "", 0, false
+	}
+	return best, runnerUp, top / total, second > 0 && second >= top*ambiguityRatio
+}
+
+// scoreRules 计算一组关键词在文件名和内容中的得分及命中的正向关键词
+func scoreRules(keywords []taxonomy.KeywordRule, lowerName, lowerContent string) (float64, []string) {
+	var score float64
+	var words []string
+	for _, rule := range keywords {
+		word := strings.ToLower(rule.Word)
+		if word == "" {
+			continue
+		}
+		weight := rule.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if rule.Negative {
+			weight = -weight
+		}
+
+		hits := 0.0
+		if countMatches(lowerName, word, rule.WholeWord, 1) > 0 {
+			hits += filenameWeight
+		}
+		if lowerContent != "" {
+			hits += contentWeight * float64(countMatches(lowerContent, word, rule.WholeWord, maxContentHits))
+		}
+		if hits == 0 {
+			continue
+		}
+		score += weight * hits
+		if !rule.Negative {
+			words = append(words, rule.Word)
+		}
+	}
+	return score, words
+}
+
+// Reason 生成打分结果的说明文字
+func (k KeywordScore) Reason(source string) string {
+	words := k.Matches
+	if len(words) > keywordReasonSize {
+		words = words[:keywordReasonSize]
+	}
+	reason := fmt.Sprintf("%s命中关键词: %s（得分 %.1f）", source, strings.Join(words, ", "), k.Score)
+	if len(k.Path) > 1 {
+		reason += "，分类路径 " + strings.Join(k.Path, " > ")
+	}
+	if k.Ambiguous {
+		reason += fmt.Sprintf("，与「%s」得分接近（%.1f）", k.RunnerUp, k.Scores[k.RunnerUp])
+	}
+	return reason
+}
+
+// countMatches 统计 word 在 text 中出现的次数，最多统计 limit 次
+// wholeWord 为 true 时要求两侧不是字母或数字
+func countMatches(text, word string, wholeWord bool, limit int) int {
+	count := 0
+	offset := 0
+	for count < limit {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		end := start + len(word)
+		offset = end
+		if wholeWord && !isWordBoundary(text, start, end) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func isWordBoundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+		return false
+	}
+	return true
+}
+
+// isWordRune 英文字母和数字视为单词的一部分，中文等字符视为分隔
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"file-classifier/internal/cache"
+	"file-classifier/internal/extractor"
+	"file-classifier/internal/models"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/taxonomy"
+	"file-classifier/internal/usage"
+)
+
+// Input 分类器输入
+// 文本内容按需提取并缓存，只有真正需要内容的分类器才会触发提取
+type Input struct {
+	Filename string // 原始文件名
+	Path     string // 磁盘路径，用于提取文本
+	RelPath  string // 相对 uploads 的路径，用于按目录匹配规则
+	Size     int64
+	MIME     string
+
+	// 分类所依据的分类体系和规则集，NewInput 时固定为当前生效的版本，
+	// 分类过程中配置被修改也不影响正在进行的分类；试运行时可替换为候选版本
+	Taxonomy *taxonomy.Taxonomy
+	Rules    *rules.Set
+
+	// Replay 不为空时 ai 分类器不发起请求，而是沿用该记录中保存的 AI 结果（用于试运行）
+	Replay *models.FileInfo
+
+	// Account AI 用量的归属（上传批次和用户）
+	Account usage.Account
+
+	hashOnce sync.Once
+	hash     string
+	hashErr  error
+
+	textOnce sync.Once
+	text     string
+	textErr  error
+
+	metaOnce sync.Once
+	meta     map[string]string
+	metaErr  error
+}
+
+// NewInput 根据磁盘文件构造分类器输入
+func NewInput(path, filename string, size int64) *Input {
+	return &Input{
+		Filename: filename,
+		Path:     path,
+		Size:     size,
+		MIME:     mime.TypeByExtension(strings.ToLower(filepath.Ext(filename))),
+		Taxonomy: taxonomy.Current(),
+		Rules:    rules.Current(),
+	}
+}
+
+// Hash 返回文件内容的哈希（首次调用时计算），用作缓存键
+func (in *Input) Hash() (string, error) {
+	in.hashOnce.Do(func() {
+		if in.Path == "" {
+			in.hashErr = fmt.Errorf("没有文件路径")
+			return
+		}
+		in.hash, in.hashErr = cache.HashFile(in.Path)
+	})
+	return in.hash, in.hashErr
+}
+
+// Text 返回提取出的文本内容（首次调用时提取）
+// 内容相同的文件复用缓存中的文本；提取失败的结果不缓存
+func (in *Input) Text() (string, error) {
+	in.textOnce.Do(func() {
+		if in.Path == "" {
+			return
+		}
+		hash, err := in.Hash()
+		if err != nil || !cache.Enabled() {
+			in.text, in.textErr = extractor.ExtractText(in.Path)
+			return
+		}
+		key := cache.TextKey(hash, extractor.VersionKey())
+		if text, ok := cache.GetText(key); ok {
+			in.text = text
+			return
+		}
+		in.text, in.textErr = extractor.ExtractText(in.Path)
+		if in.textErr == nil {
+			cache.PutText(key, hash, in.text)
+		}
+	})
+	return in.text, in.textErr
+}
+
+// SetText 预先设置文本内容，跳过提取
+func (in *Input) SetText(text string) {
+	in.textOnce.Do(func() {})
+	in.text, in.textErr = text, nil
+}
+
+// Metadata 返回文档元数据（首次调用时提取）
+func (in *Input) Metadata() (map[string]string, error) {
+	in.metaOnce.Do(func() {
+		if in.Path == "" {
+			in.meta = map[string]string{}
+			return
+		}
+		in.meta, in.metaErr = extractor.ExtractMetadata(in.Path)
+	})
+	return in.meta, in.metaErr
+}
+
+// Result 分类器输出
+type Result struct {
+	Category   string   `json:"category"`
+	Confidence float64  `json:"confidence"`        // 0~1
+	Matches    []string `json:"matches,omitempty"` // 命中的关键词或规则名
+	Reason     string   `json:"reason"`
+	Ambiguous  bool     `json:"ambiguous,omitempty"` // 多个分类得分接近
+	Classifier string   `json:"classifier"`          // 给出结果的分类器名称，由流水线填充
+}
+
+// Classifier 分类器接口
+// 无法判断时返回 "未分类"；出现错误时流水线会跳到下一级
+//
+// 注意：所有实现应保证线程安全
+type Classifier interface {
+	Name() string
+	Classify(in *Input) (Result, error)
+}
+
+// ClassifierFactory 根据配置项创建分类器
+type ClassifierFactory func(options map[string]string) (Classifier, error)
+
+var (
+	factories     = make(map[string]ClassifierFactory)
+	factoriesLock sync.RWMutex
+)
+
+// RegisterClassifier 在 init() 中调用，按名称注册分类器
+func RegisterClassifier(name string, factory ClassifierFactory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	factories[name] = factory
+}
+
+// ClassifierNames 返回已注册的分类器名称
+func ClassifierNames() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StageConfig 流水线中一级的配置
+type StageConfig struct {
+	Classifier string            `json:"classifier"`
+	Threshold  float64           `json:"threshold"` // 置信度达到阈值才采纳该级结果
+	Options    map[string]string `json:"options,omitempty"`
+}
+
+// PipelineConfig 流水线配置文件结构
+type PipelineConfig struct {
+	Stages []StageConfig `json:"stages"`
+}
+
+// DefaultPipelineConfig 默认流水线：规则 → 文件名关键词 → AI → 本地内容关键词
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{
+		Stages: []StageConfig{
+			{Classifier: "rules"},
+			{Classifier: "filename"},
+			{Classifier: "ai"},
+			{Classifier: "content"},
+		},
+	}
+}
+
+type stage struct {
+	classifier Classifier
+	threshold  float64
+}
+
+// Pipeline 按顺序级联多个分类器
+type Pipeline struct {
+	stages []stage
+}
+
+// NewPipeline 根据配置构建流水线
+func NewPipeline(cfg PipelineConfig) (*Pipeline, error) {
+	if len(cfg.Stages) == 0 {
+		return nil, fmt.Errorf("流水线至少需要一级分类器")
+	}
+
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+
+	p := &Pipeline{}
+	for i, sc := range cfg.Stages {
+		factory, ok := factories[sc.Classifier]
+		if !ok {
+			return nil, fmt.Errorf("第 %d 级: 未知的分类器 %q", i+1, sc.Classifier)
+		}
+		if sc.Threshold < 0 || sc.Threshold > 1 {
+			return nil, fmt.Errorf("第 %d 级: 阈值必须在 0~1 之间", i+1)
+		}
+		c, err := factory(sc.Options)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 级: 创建分类器 %q 失败: %v", i+1, sc.Classifier, err)
+		}
+		p.stages = append(p.stages, stage{classifier: c, threshold: sc.Threshold})
+	}
+	return p, nil
+}
+
+// Classify 依次运行各级分类器，返回第一个达到阈值的结果
+// 所有分类器都未给出结果时返回 "未分类"，Classifier 为 "failed"
+func (p *Pipeline) Classify(in *Input) Result {
+	for _, s := range p.stages {
+		name := s.classifier.Name()
+		result, err := s.classifier.Classify(in)
+		if err != nil {
+			log.Printf("分类器 %s 处理失败: %s, %v", name, in.Filename, err)
+			continue
+		}
+		if result.Category == "" || result.Category == "未分类" {
+			continue
+		}
+		// 模型或规则可能指向已被删除或重命名的分类
+		if !in.Taxonomy.Has(result.Category) {
+			log.Printf("分类器 %s 返回的分类不存在: %s -> %s", name, in.Filename, result.Category)
+			continue
+		}
+		if result.Confidence < s.threshold {
+			log.Printf("分类器 %s 置信度不足: %s -> %s (%.2f < %.2f)",
+				name, in.Filename, result.Category, result.Confidence, s.threshold)
+			continue
+		}
+		result.Classifier = name
+		return result
+	}
+	return Result{Category: "未分类", Classifier: "failed"}
+}
+
+var (
+	currentPipeline *Pipeline
+	currentConfig   PipelineConfig
+	pipelineLock    sync.RWMutex
+)
+
+// ReadPipelineConfig 读取流水线配置文件；文件不存在时返回默认配置
+func ReadPipelineConfig(path string) (PipelineConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.Printf("未找到流水线配置 %s，使用默认配置", path)
+		return DefaultPipelineConfig(), nil
+	}
+	if err != nil {
+		return PipelineConfig{}, fmt.Errorf("读取流水线配置失败: %v", err)
+	}
+	var cfg PipelineConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return PipelineConfig{}, fmt.Errorf("解析流水线配置失败: %v", err)
+	}
+	return cfg, nil
+}
+
+// LoadPipeline 从配置文件加载流水线；文件不存在时使用默认配置
+func LoadPipeline(path string) error {
+	cfg, err := ReadPipelineConfig(path)
+	if err != nil {
+		return err
+	}
+	p, err := NewPipeline(cfg)
+	if err != nil {
+		return err
+	}
+	log.Printf("已加载流水线配置: %s", path)
+
+	pipelineLock.Lock()
+	currentPipeline = p
+	currentConfig = cfg
+	pipelineLock.Unlock()
+	return nil
+}
+
+// CurrentPipelineConfig 返回当前生效的流水线配置
+func CurrentPipelineConfig() PipelineConfig {
+	pipelineLock.RLock()
+	defer pipelineLock.RUnlock()
+	if currentPipeline == nil {
+		return DefaultPipelineConfig()
+	}
+	return currentConfig
+}
+
+// RequestPipelineConfig 将 API 请求中的流水线配置转为可运行的配置：请求只能指定各级的分类器和阈值，
+// 分类器选项（AI 服务地址、密钥、模拟脚本等）只能来自服务器上的配置文件，沿用当前配置中同名分类器的选项
+func RequestPipelineConfig(req PipelineConfig) (PipelineConfig, error) {
+	current := CurrentPipelineConfig()
+	options := make(map[string]map[string]string)
+	for _, sc := range current.Stages {
+		if _, ok := options[sc.Classifier]; !ok {
+			options[sc.Classifier] = sc.Options
+		}
+	}
+	cfg := PipelineConfig{Stages: make([]StageConfig, 0, len(req.Stages))}
+	for i, sc := range req.Stages {
+		if len(sc.Options) > 0 {
+			return PipelineConfig{}, fmt.Errorf("第 %d 级: 请求中不能设置分类器选项，请修改服务器上的流水线配置文件", i+1)
+		}
+		cfg.Stages = append(cfg.Stages, StageConfig{
+			Classifier: sc.Classifier,
+			Threshold:  sc.Threshold,
+			Options:    options[sc.Classifier],
+		})
+	}
+	return cfg, nil
+}
+
+// CurrentPipeline 返回当前生效的流水线，未加载时使用默认配置
+func CurrentPipeline() *Pipeline {
+	pipelineLock.RLock()
+	p := currentPipeline
+	pipelineLock.RUnlock()
+	if p != nil {
+		return p
+	}
+
+	p, err := NewPipeline(DefaultPipelineConfig())
+	if err != nil {
+		// 默认配置只引用内置分类器，不应失败
+		panic(err)
+	}
+	pipelineLock.Lock()
+	if currentPipeline == nil {
+		currentPipeline = p
+	}
+	p = currentPipeline
+	pipelineLock.Unlock()
+	return p
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/config"
+	"file-classifier/internal/models"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/taxonomy"
+)
+
+const (
+	previewConcurrency = 8 // 试运行时同时处理的文件数
+
+	// TaxonomyWatchInterval 检查分类体系文档是否变化的间隔
+	TaxonomyWatchInterval = 2 * time.Second
+)
+
+// CategoryMove 一组从 From 移到 To 的文件
+type CategoryMove struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// FileChange 单个文件在候选配置下的分类变化
+type FileChange struct {
+	Path       string `json:"path"`
+	Current    string `json:"current"`
+	Proposed   string `json:"proposed"`
+	Classifier string `json:"classifier"`
+	Changed    bool   `json:"changed"`
+}
+
+// TaxonomyDiff 候选分类体系与当前分类体系的差异
+type TaxonomyDiff struct {
+	FromVersion int            `json:"fromVersion"`
+	Added       []string       `json:"added"`
+	Removed     []string       `json:"removed"`
+	Modified    []string       `json:"modified"`
+	Files       int            `json:"files"`   // 参与比较的文件数
+	Changed     int            `json:"changed"` // 分类会发生变化的文件数
+	Moves       []CategoryMove `json:"moves"`
+	Changes     []FileChange   `json:"changes"`           // 分类会变化的文件
+	Results     []FileChange   `json:"results,omitempty"` // 所有文件，仅 WhatIf 返回
+}
+
+// WatchStatus 分类体系文档的监视状态
+type WatchStatus struct {
+	File        string    `json:"file"`
+	LastChecked time.Time `json:"lastChecked"`
+	LastApplied time.Time `json:"lastApplied"`
+	LastError   string    `json:"lastError,omitempty"` // 最近一次被拒绝的重新加载
+	LastErrorAt time.Time `json:"lastErrorAt"`
+}
+
+var (
+	watchStatus WatchStatus
+	watchDigest [sha256.Size]byte
+	watchLock   sync.Mutex
+)
+
+// LoadTaxonomyFile 启动时导入分类体系文档，规则同 applyTaxonomyFile；文件不存在时忽略
+func LoadTaxonomyFile(path string) error {
+	watchLock.Lock()
+	defer watchLock.Unlock()
+	watchStatus.File = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取分类体系文档失败: %v", err)
+	}
+	watchDigest = sha256.Sum256(data)
+	if err := applyTaxonomyFile(path, data); err != nil {
+		if errors.Is(err, errStaleDocument) {
+			log.Printf("跳过分类体系文档 %s: %v", path, err)
+			return nil
+		}
+		return fmt.Errorf("导入分类体系文档失败: %v", err)
+	}
+	watchStatus.LastApplied = time.Now()
+	log.Printf("已导入分类体系文档 %s，当前版本 %d", path, taxonomy.Current().Version)
+	return nil
+}
+
+// WatchTaxonomyFile 定期检查分类体系文档，内容变化时校验并原子替换当前分类体系
+// 校验失败时保留当前分类体系并记录错误；正在进行的分类继续使用旧快照
+func WatchTaxonomyFile(path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reloadTaxonomyFile(path)
+	}
+}
+
+// reloadTaxonomyFile 文档内容与上次不同时重新加载
+func reloadTaxonomyFile(path string) {
+	watchLock.Lock()
+	defer watchLock.Unlock()
+	watchStatus.File = path
+	watchStatus.LastChecked = time.Now()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// 文件被删除或暂时不可读时保持当前分类体系
+		return
+	}
+	digest := sha256.Sum256(data)
+	if digest == watchDigest {
+		return
+	}
+	watchDigest = digest
+
+	if err := applyTaxonomyFile(path, data); err != nil {
+		watchStatus.LastError = err.Error()
+		watchStatus.LastErrorAt = time.Now()
+		log.Printf("分类体系文档 %s 未生效，保留当前版本 %d: %v", path, taxonomy.Current().Version, err)
+		return
+	}
+	watchStatus.LastApplied = time.Now()
+	watchStatus.LastError = ""
+	log.Printf("已重新加载分类体系文档 %s，当前版本 %d", path, taxonomy.Current().Version)
+}
+
+// errStaleDocument 文档版本号不高于当前版本
+var errStaleDocument = errors.New("文档版本号不高于当前版本")
+
+// applyTaxonomyFile 校验并应用分类体系文档；启动导入和运行中重新加载使用同一规则：
+// 文档版本号必须大于当前版本，否则返回 errStaleDocument，避免旧文档覆盖通过接口所做的修改
+func applyTaxonomyFile(path string, data []byte) error {
+	doc, err := taxonomy.ParseDocument(data, taxonomy.FormatFromPath(path))
+	if err != nil {
+		return err
+	}
+	if current := taxonomy.Current().Version; doc.Version <= current {
+		return fmt.Errorf("%w（文档版本 %d，当前版本 %d）", errStaleDocument, doc.Version, current)
+	}
+	_, err = taxonomy.Apply(doc, "file")
+	return err
+}
+
+// GetWatchStatus 返回分类体系文档的监视状态
+func GetWatchStatus() WatchStatus {
+	watchLock.Lock()
+	defer watchLock.Unlock()
+	return watchStatus
+}
+
+// PreviewDocument 试运行：计算候选文档生效后各文件的分类变化，不修改任何数据
+func PreviewDocument(doc *taxonomy.Document) (*TaxonomyDiff, error) {
+	diff, err := WhatIf(doc)
+	if diff != nil {
+		diff.Results = nil
+	}
+	return diff, err
+}
+
+// WhatIf 使用候选分类体系和规则集对文件目录中的每个文件重新分类，
+// 返回每个文件的当前分类与候选分类以及汇总的移动数；不修改文件目录和当前配置
+func WhatIf(doc *taxonomy.Document) (*TaxonomyDiff, error) {
+	set, err := doc.Validate()
+	if err != nil {
+		return nil, err
+	}
+	current := taxonomy.Current()
+	diff := compareTaxonomies(current, &doc.Taxonomy)
+	diff.FromVersion = current.Version
+
+	files := catalog.List()
+	proposed := make([]Result, len(files))
+	semaphore := make(chan struct{}, previewConcurrency)
+	var wg sync.WaitGroup
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			proposed[i] = previewFile(files[i], &doc.Taxonomy, set)
+		}(i)
+	}
+	wg.Wait()
+
+	moves := make(map[[2]string]int)
+	diff.Files = len(files)
+	diff.Results = make([]FileChange, 0, len(files))
+	for i, info := range files {
+		change := FileChange{
+			Path:       info.Path,
+			Current:    info.Category,
+			Proposed:   proposed[i].Category,
+			Classifier: proposed[i].Classifier,
+			Changed:    proposed[i].Category != info.Category,
+		}
+		diff.Results = append(diff.Results, change)
+		if !change.Changed {
+			continue
+		}
+		diff.Changed++
+		moves[[2]string{info.Category, proposed[i].Category}]++
+		diff.Changes = append(diff.Changes, change)
+	}
+	for key, count := range moves {
+		diff.Moves = append(diff.Moves, CategoryMove{From: key[0], To: key[1], Count: count})
+	}
+	sort.Slice(diff.Moves, func(i, j int) bool {
+		if diff.Moves[i].Count != diff.Moves[j].Count {
+			return diff.Moves[i].Count > diff.Moves[j].Count
+		}
+		if diff.Moves[i].From != diff.Moves[j].From {
+			return diff.Moves[i].From < diff.Moves[j].From
+		}
+		return diff.Moves[i].To < diff.Moves[j].To
+	})
+	return diff, nil
+}
+
+// previewFile 使用候选分类体系和规则集对已入库的文件重新分类
+// 手动分类保持不变（分类被删除时视为未分类），AI 结果沿用已有记录而不重新请求
+func previewFile(info models.FileInfo, tax *taxonomy.Taxonomy, set *rules.Set) Result {
+	if info.Override != nil {
+		if tax.Has(info.Override.Category) {
+			return Result{Category: info.Override.Category, Classifier: "manual"}
+		}
+		return Result{Category: taxonomy.Unclassified, Classifier: "manual"}
+	}
+
+	in := NewInput(filepath.Join(config.UploadDir, info.Path), info.Name, info.Size)
+	in.RelPath = info.Path
+	in.Taxonomy = tax
+	in.Rules = set
+	in.Replay = &info
+	return CurrentPipeline().Classify(in)
+}
+
+// compareTaxonomies 列出新增、删除和修改过的分类
+func compareTaxonomies(from, to *taxonomy.Taxonomy) *TaxonomyDiff {
+	diff := &TaxonomyDiff{Added: []string{}, Removed: []string{}, Modified: []string{}, Moves: []CategoryMove{}, Changes: []FileChange{}}
+	for _, name := range to.Names() {
+		old, ok := from.Get(name)
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if cat, _ := to.Get(name); !reflect.DeepEqual(old, cat) {
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	for _, name := range from.Names() {
+		if !to.Has(name) {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"file-classifier/internal/bayes"
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/config"
+	"file-classifier/internal/extractor"
+	"file-classifier/internal/utils"
+)
+
+// TrainReport 模型训练报告
+type TrainReport struct {
+	Samples      int            `json:"samples"`
+	TrainSamples int            `json:"trainSamples"`
+	TestSamples  int            `json:"testSamples"`
+	Accuracy     float64        `json:"accuracy"` // 留出集准确率
+	Categories   map[string]int `json:"categories"`
+	TrainedAt    time.Time      `json:"trainedAt"`
+}
+
+// TrainingSamples 从文件目录收集经用户确认或纠正过分类的文件作为训练样本
+func TrainingSamples() []bayes.Sample {
+	var samples []bayes.Sample
+	for _, info := range catalog.List() {
+		if info.Override == nil {
+			continue
+		}
+		samples = append(samples, bayes.Sample{
+			ID:       info.Path,
+			Text:     trainingText(filepath.Join(config.UploadDir, info.Path), info.Name),
+			Category: info.Override.Category,
+		})
+	}
+	return samples
+}
+
+// trainingText 训练和预测使用相同的文本：文件标题 + 提取的内容
+func trainingText(path, filename string) string {
+	text := utils.GetSafeFileName(filename)
+	if content, err := extractor.ExtractText(path); err == nil {
+		text += "\n" + content
+	}
+	return text
+}
+
+// TrainModel 按 testRatio 划分留出集评估准确率，再用全部样本训练并保存模型
+func TrainModel(testRatio float64) (TrainReport, error) {
+	if testRatio < 0 || testRatio >= 1 {
+		return TrainReport{}, fmt.Errorf("测试集比例必须在 0~1 之间")
+	}
+
+	samples := TrainingSamples()
+	report := TrainReport{Samples: len(samples), Categories: make(map[string]int)}
+	for _, s := range samples {
+		report.Categories[s.Category]++
+	}
+	if len(report.Categories) < 2 {
+		return report, fmt.Errorf("训练样本不足：至少需要两个分类的手动确认文件，当前 %d 个样本", len(samples))
+	}
+
+	// 按样本ID哈希划分，保证同一文件每次都落在同一侧
+	var train, test []bayes.Sample
+	for _, s := range samples {
+		h := sha256.Sum256([]byte(s.ID))
+		if float64(binary.BigEndian.Uint32(h[:4])%1000) < testRatio*1000 {
+			test = append(test, s)
+		} else {
+			train = append(train, s)
+		}
+	}
+	report.TrainSamples = len(train)
+	report.TestSamples = len(test)
+	if len(test) > 0 && len(train) > 0 {
+		report.Accuracy = bayes.Train(train).Evaluate(test)
+	}
+
+	model := bayes.Train(samples)
+	model.Accuracy = report.Accuracy
+	model.TestSamples = report.TestSamples
+	report.TrainedAt = model.TrainedAt
+
+	if err := bayes.SetCurrent(model, config.BayesModelFile); err != nil {
+		return report, err
+	}
+	log.Printf("贝叶斯模型训练完成: %d 个样本, 留出集准确率 %.2f (%d 个)",
+		report.Samples, report.Accuracy, report.TestSamples)
+	return report, nil
+}
@@ -0,0 +1,253 @@
+package taxonomy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"file-classifier/internal/rules"
+	"file-classifier/internal/utils"
+)
+
+// 分类体系文档：用于导入导出和版本历史，包含分类、关键词、描述、策略以及分类规则。
+// 支持 JSON 和 YAML 两种格式，字段名与 JSON 一致（YAML 通过 JSON 转换，无需额外的 yaml 标签）。
+
+// historyLimit 最多保留的历史版本数
+const historyLimit = 50
+
+// Document 分类体系文档
+type Document struct {
+	Taxonomy
+	Rules []rules.Rule `json:"rules"` // 为 null 时导入不修改现有规则
+}
+
+// VersionInfo 历史版本摘要
+type VersionInfo struct {
+	Version    int    `json:"version"`
+	UpdatedAt  string `json:"updatedAt"`
+	Categories int    `json:"categories"`
+	Rules      int    `json:"rules"`
+}
+
+// FormatFromPath 根据文件扩展名判断文档格式
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return "json"
+	}
+}
+
+// ParseDocument 解析文档；format 为空时根据内容自动判断
+func ParseDocument(data []byte, format string) (*Document, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("文档内容为空")
+	}
+	if format == "" {
+		format = "yaml"
+		if trimmed[0] == '{' {
+			format = "json"
+		}
+	}
+
+	if format == "yaml" {
+		var raw interface{}
+		if err := yaml.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %v", err)
+		}
+		converted, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("转换 YAML 失败: %v", err)
+		}
+		trimmed = converted
+	}
+
+	var doc Document
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析分类体系文档失败: %v", err)
+	}
+	return &doc, nil
+}
+
+// Marshal 按指定格式（json 或 yaml）序列化文档
+func (d *Document) Marshal(format string) ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil || format != "yaml" {
+		return data, err
+	}
+
+	// JSON 也是合法的 YAML：解析为节点树后去掉流式风格，输出块格式并保留字段顺序
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearStyle(&node)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	return buf.Bytes(), nil
+}
+
+// clearStyle 清除节点的流式和引号风格，字符串值仍会在需要时自动加引号
+func clearStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// Export 导出当前分类体系和规则
+func Export() *Document {
+	return &Document{Taxonomy: *Current().Clone(), Rules: rules.List()}
+}
+
+// Validate 校验文档：分类体系有效、规则有效且规则指向的分类存在
+func (d *Document) Validate() (*rules.Set, error) {
+	if err := d.Taxonomy.Validate(); err != nil {
+		return nil, err
+	}
+	list := d.Rules
+	if list == nil {
+		list = rules.List()
+	}
+	set, err := rules.Compile(list)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range set.List() {
+		if r.Category != "" && !d.Taxonomy.Has(r.Category) {
+			return nil, fmt.Errorf("规则 %q 指向的分类不存在: %s", r.Name, r.Category)
+		}
+	}
+	return set, nil
+}
+
+// Apply 校验并应用文档，生成新的分类体系版本；文档带规则时同时替换规则
+// source 记录文档来源（file、import、rollback）；校验或保存失败时不做任何修改
+func Apply(d *Document, source string) (int, error) {
+	if _, err := d.Validate(); err != nil {
+		return 0, err
+	}
+	// 先替换规则，新版本的历史快照才包含新规则；分类体系替换失败时恢复原规则
+	previous := rules.List()
+	if d.Rules != nil {
+		if err := rules.Replace(d.Rules); err != nil {
+			return 0, err
+		}
+	}
+	if err := Replace(&d.Taxonomy, source); err != nil {
+		if d.Rules != nil {
+			if restoreErr := rules.Replace(previous); restoreErr != nil {
+				log.Printf("恢复分类规则失败: %v", restoreErr)
+			}
+		}
+		return 0, err
+	}
+	return Current().Version, nil
+}
+
+// SnapshotRules 规则修改后更新当前版本的历史快照，回滚到该版本时恢复其最后生效的规则
+func SnapshotRules() {
+	updateLock.Lock()
+	defer updateLock.Unlock()
+	if err := saveHistory(Current()); err != nil {
+		log.Printf("保存分类体系历史版本失败: %v", err)
+	}
+}
+
+// LoadVersion 读取历史版本的文档
+func LoadVersion(version int) (*Document, error) {
+	if historyDir == "" {
+		return nil, fmt.Errorf("未启用版本历史")
+	}
+	var doc Document
+	if err := utils.ReadJSONFile(historyPath(version), &doc); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("版本 %d 不存在", version)
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// History 返回保存的历史版本摘要，按版本号从新到旧排列
+func History() ([]VersionInfo, error) {
+	versions, err := historyVersions()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]VersionInfo, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		doc, err := LoadVersion(versions[i])
+		if err != nil {
+			continue
+		}
+		result = append(result, VersionInfo{
+			Version:    doc.Version,
+			UpdatedAt:  doc.UpdatedAt.Format("2006-01-02 15:04:05"),
+			Categories: len(doc.Categories),
+			Rules:      len(doc.Rules),
+		})
+	}
+	return result, nil
+}
+
+// saveHistory 保存版本快照并清理过旧的版本
+func saveHistory(t *Taxonomy) error {
+	if historyDir == "" {
+		return nil
+	}
+	doc := Document{Taxonomy: *t, Rules: rules.List()}
+	if err := utils.WriteJSONFile(historyPath(t.Version), doc); err != nil {
+		return err
+	}
+	versions, err := historyVersions()
+	if err != nil {
+		return err
+	}
+	for len(versions) > historyLimit {
+		os.Remove(historyPath(versions[0]))
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// historyVersions 返回历史目录中的版本号，从小到大排列
+func historyVersions() ([]int, error) {
+	entries, err := os.ReadDir(historyDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取版本历史失败: %v", err)
+	}
+	var versions []int
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".json")
+		if v, err := strconv.Atoi(name); err == nil && !e.IsDir() {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+func historyPath(version int) string {
+	return filepath.Join(historyDir, strconv.Itoa(version)+".json")
+}
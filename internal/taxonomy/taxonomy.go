@@ -0,0 +1,468 @@
+package taxonomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"file-classifier/internal/utils"
+)
+
+// 分类体系（taxonomy）：树形分类，每个节点有自己的关键词。
+// 当前分类体系是不可变快照，修改时复制、校验后整体原子替换：读取方拿到的 *Taxonomy 不会再被修改。
+
+// Unclassified 未分类，隐含存在，不属于分类树
+const Unclassified = "未分类"
+
+// KeywordRule 分类关键词规则
+type KeywordRule struct {
+	Word      string  `json:"word"`
+	Weight    float64 `json:"weight"`              // 权重，越具体的词权重越高
+	Negative  bool    `json:"negative,omitempty"`  // 反向关键词：命中时扣分
+	WholeWord bool    `json:"wholeWord,omitempty"` // 仅整词匹配（适用于 cv、bill 等短英文词）
+}
+
+// Category 分类树中的一个节点
+type Category struct {
+	Name        string        `json:"name"`
+	Parent      string        `json:"parent,omitempty"` // 为空表示顶级分类
+	Keywords    []KeywordRule `json:"keywords"`
+	Description string        `json:"description,omitempty"`
+	Icon        string        `json:"icon,omitempty"`  // 前端图标，如 "fas fa-file-contract"
+	Order       int           `json:"order,omitempty"` // 显示顺序，越小越靠前
+	Protected   bool          `json:"protected"`       // 受保护的分类不能删除或重命名
+}
+
+// Policy 分类管理策略
+type Policy struct {
+	MaxCustomCategories int `json:"maxCustomCategories"` // 自定义分类数量上限，0 表示不限
+	MaxDepth            int `json:"maxDepth"`            // 分类树最大层数，0 表示不限
+}
+
+// SchemaVersion 分类文件的格式版本：1 起分类带有 protected 字段，更早的文件没有 schema 字段
+const SchemaVersion = 1
+
+// Taxonomy 完整的分类体系
+type Taxonomy struct {
+	Schema     int        `json:"schema"`  // 文件格式版本，保存时写入 SchemaVersion
+	Version    int        `json:"version"` // 每次修改递增
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	Categories []Category `json:"categories"`
+	Policy     Policy     `json:"policy"`
+}
+
+// Default 内置的默认分类体系
+func Default() *Taxonomy {
+	return &Taxonomy{
+		Schema: SchemaVersion,
+		Categories: []Category{
+			{Name: "合同", Description: "包含合同协议等相关文件", Icon: "fas fa-file-contract", Order: 1, Protected: true, Keywords: []KeywordRule{
+				{Word: "合同", Weight: 3}, {Word: "协议", Weight: 3}, {Word: "契约", Weight: 3},
+				{Word: "contract", Weight: 3, WholeWord: true}, {Word: "agreement", Weight: 3, WholeWord: true},
+				{Word: "签署", Weight: 1}, {Word: "合作", Weight: 0.5},
+			}},
+			{Name: "简历", Description: "包含个人简历和求职相关文件", Icon: "fas fa-user-tie", Order: 2, Protected: true, Keywords: []KeywordRule{
+				{Word: "个人简历", Weight: 4}, {Word: "简历", Weight: 3}, {Word: "履历", Weight: 3},
+				{Word: "resume", Weight: 3, WholeWord: true}, {Word: "cv", Weight: 2, WholeWord: true},
+				{Word: "求职", Weight: 1}, {Word: "应聘", Weight: 1},
+			}},
+			{Name: "发票", Description: "包含发票收据等财务文件", Icon: "fas fa-receipt", Order: 3, Protected: true, Keywords: []KeywordRule{
+				{Word: "发票", Weight: 3}, {Word: "invoice", Weight: 3, WholeWord: true},
+				{Word: "票据", Weight: 2}, {Word: "收据", Weight: 2},
+				{Word: "账单", Weight: 1}, {Word: "bill", Weight: 1, WholeWord: true}, {Word: "费用", Weight: 0.5},
+			}},
+			{Name: "论文", Description: "包含学术论文和研究报告", Icon: "fas fa-graduation-cap", Order: 4, Protected: true, Keywords: []KeywordRule{
+				{Word: "毕业论文", Weight: 4}, {Word: "论文", Weight: 3}, {Word: "thesis", Weight: 3, WholeWord: true},
+				{Word: "研究报告", Weight: 2}, {Word: "paper", Weight: 2, WholeWord: true},
+				{Word: "学术", Weight: 1}, {Word: "期刊", Weight: 1}, {Word: "研究", Weight: 0.5}, {Word: "报告", Weight: 0.5},
+			}},
+		},
+		Policy: Policy{MaxCustomCategories: 3},
+	}
+}
+
+// Clone 深拷贝
+func (t *Taxonomy) Clone() *Taxonomy {
+	c := &Taxonomy{Schema: t.Schema, Version: t.Version, UpdatedAt: t.UpdatedAt, Policy: t.Policy, Categories: make([]Category, len(t.Categories))}
+	for i, cat := range t.Categories {
+		cat.Keywords = append([]KeywordRule(nil), cat.Keywords...)
+		c.Categories[i] = cat
+	}
+	return c
+}
+
+// Get 按名称查找分类
+func (t *Taxonomy) Get(name string) (Category, bool) {
+	for _, cat := range t.Categories {
+		if cat.Name == name {
+			return cat, true
+		}
+	}
+	return Category{}, false
+}
+
+// Has 判断分类是否存在（未分类始终存在）
+func (t *Taxonomy) Has(name string) bool {
+	if name == Unclassified {
+		return true
+	}
+	_, ok := t.Get(name)
+	return ok
+}
+
+// Names 返回所有分类名（不含未分类），按名称排序
+func (t *Taxonomy) Names() []string {
+	names := make([]string, 0, len(t.Categories))
+	for _, cat := range t.Categories {
+		names = append(names, cat.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sorted 返回按显示顺序（Order、名称）排列的分类
+func (t *Taxonomy) Sorted() []Category {
+	list := append([]Category(nil), t.Categories...)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Order != list[j].Order {
+			return list[i].Order < list[j].Order
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Children 返回直接子分类名，按名称排序；parent 为空时返回顶级分类
+func (t *Taxonomy) Children(parent string) []string {
+	var names []string
+	for _, cat := range t.Categories {
+		if cat.Parent == parent {
+			names = append(names, cat.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Descendants 返回所有后代分类名（不含自身）
+func (t *Taxonomy) Descendants(name string) []string {
+	var result []string
+	for _, child := range t.Children(name) {
+		result = append(result, child)
+		result = append(result, t.Descendants(child)...)
+	}
+	return result
+}
+
+// Path 返回从顶级分类到该分类的路径；分类不存在时只包含自身
+func (t *Taxonomy) Path(name string) []string {
+	var path []string
+	for name != "" && len(path) <= len(t.Categories) {
+		path = append([]string{name}, path...)
+		cat, ok := t.Get(name)
+		if !ok {
+			break
+		}
+		name = cat.Parent
+	}
+	return path
+}
+
+// builtinNames 内置分类名；内置分类不计入自定义分类数量，与是否受保护无关
+var builtinNames = func() map[string]bool {
+	names := make(map[string]bool)
+	for _, cat := range Default().Categories {
+		names[cat.Name] = true
+	}
+	return names
+}()
+
+// IsBuiltin 判断是否为内置分类
+func IsBuiltin(name string) bool {
+	return builtinNames[name]
+}
+
+// CustomCount 统计自定义（非内置）分类的数量
+func (t *Taxonomy) CustomCount() int {
+	count := 0
+	for _, cat := range t.Categories {
+		if !IsBuiltin(cat.Name) {
+			count++
+		}
+	}
+	return count
+}
+
+// Validate 校验分类体系：名称唯一、父分类存在、无环、满足策略
+func (t *Taxonomy) Validate() error {
+	seen := make(map[string]bool)
+	for _, cat := range t.Categories {
+		name := strings.TrimSpace(cat.Name)
+		if name == "" {
+			return fmt.Errorf("分类名称不能为空")
+		}
+		if name != cat.Name {
+			return fmt.Errorf("分类名称 %q 首尾不能有空白", cat.Name)
+		}
+		if name == Unclassified {
+			return fmt.Errorf("%q 为保留分类", Unclassified)
+		}
+		if seen[name] {
+			return fmt.Errorf("分类 %q 重复", name)
+		}
+		seen[name] = true
+	}
+	for _, cat := range t.Categories {
+		if cat.Parent != "" && !seen[cat.Parent] {
+			return fmt.Errorf("分类 %q 的父分类 %q 不存在", cat.Name, cat.Parent)
+		}
+		depth := len(t.Path(cat.Name))
+		if depth > len(t.Categories) {
+			return fmt.Errorf("分类 %q 的父子关系存在环", cat.Name)
+		}
+		if t.Policy.MaxDepth > 0 && depth > t.Policy.MaxDepth {
+			return fmt.Errorf("分类 %q 超过最大层数 %d", cat.Name, t.Policy.MaxDepth)
+		}
+	}
+	if t.Policy.MaxCustomCategories > 0 && t.CustomCount() > t.Policy.MaxCustomCategories {
+		return fmt.Errorf("新增分类数量已达上限（最多%d个），请删除一些分类后再添加", t.Policy.MaxCustomCategories)
+	}
+	return nil
+}
+
+// Status 当前生效的分类体系版本信息
+type Status struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"` // 该版本的生成时间
+	LoadedAt  time.Time `json:"loadedAt"`  // 该版本在本进程中生效的时间
+	Source    string    `json:"source"`    // default、store、api、file、import、rollback
+}
+
+// snapshot 不可变的分类体系快照，整体原子替换
+type snapshot struct {
+	taxonomy *Taxonomy
+	status   Status
+}
+
+var (
+	active     atomic.Pointer[snapshot]
+	updateLock sync.Mutex // 串行化修改，读取无需加锁
+	storePath  string
+	historyDir string
+)
+
+func init() {
+	activate(Default(), "default")
+}
+
+// 分类管理错误
+var (
+	ErrCategoryExists    = errors.New("分类已存在")
+	ErrCategoryNotFound  = errors.New("分类不存在")
+	ErrCategoryProtected = errors.New("受保护的分类无法删除或重命名")
+)
+
+// Init 从文件加载分类体系；文件不存在时使用默认分类体系
+// 每个版本的快照保存在 history 目录中，用于回滚
+func Init(path, history string) error {
+	t, source := &Taxonomy{}, "store"
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		t, source = Default(), "default"
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, t); err != nil {
+			return fmt.Errorf("解析 %s 失败: %v", path, err)
+		}
+		// 格式版本 1 之前的分类文件没有 protected 字段
+		if t.Schema < SchemaVersion {
+			protectBuiltins(t)
+			t.Schema = SchemaVersion
+		}
+	}
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("分类体系无效: %v", err)
+	}
+
+	updateLock.Lock()
+	defer updateLock.Unlock()
+	storePath = path
+	historyDir = history
+	activate(t, source)
+	return nil
+}
+
+// protectBuiltins 迁移格式版本 1 之前的分类文件：将内置分类标记为受保护
+func protectBuiltins(t *Taxonomy) {
+	for _, builtin := range Default().Categories {
+		if i := t.index(builtin.Name); i >= 0 {
+			t.Categories[i].Protected = true
+		}
+	}
+}
+
+// Current 返回当前分类体系快照；返回值只读，修改请使用 Update
+// 快照被替换后，已取得旧快照的分类过程仍使用旧快照完成
+func Current() *Taxonomy {
+	return active.Load().taxonomy
+}
+
+// CurrentStatus 返回当前分类体系的版本、生效时间和来源
+func CurrentStatus() Status {
+	return active.Load().status
+}
+
+// Update 在当前分类体系的副本上执行修改，校验通过后生成新版本、持久化并替换
+func Update(fn func(t *Taxonomy) error) error {
+	updateLock.Lock()
+	defer updateLock.Unlock()
+
+	next := Current().Clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+	return install(next, "api")
+}
+
+// Replace 用 next 整体替换当前分类体系，source 记录替换来源
+// next 的版本号不大于当前版本时使用当前版本号加一；校验失败时保留当前分类体系
+func Replace(next *Taxonomy, source string) error {
+	updateLock.Lock()
+	defer updateLock.Unlock()
+	return install(next.Clone(), source)
+}
+
+// install 校验、持久化 next 并设为当前分类体系，调用方需持有 updateLock
+func install(next *Taxonomy, source string) error {
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	if version := Current().Version; next.Version <= version {
+		next.Version = version + 1
+	}
+	next.Schema = SchemaVersion
+	next.UpdatedAt = time.Now()
+	if storePath != "" {
+		if err := utils.WriteJSONFile(storePath, next); err != nil {
+			return fmt.Errorf("保存分类体系失败: %v", err)
+		}
+	}
+	if err := saveHistory(next); err != nil {
+		log.Printf("保存分类体系历史版本失败: %v", err)
+	}
+	activate(next, source)
+	return nil
+}
+
+// activate 原子替换当前快照
+func activate(t *Taxonomy, source string) {
+	active.Store(&snapshot{
+		taxonomy: t,
+		status:   Status{Version: t.Version, UpdatedAt: t.UpdatedAt, LoadedAt: time.Now(), Source: source},
+	})
+}
+
+// AddCategory 新增分类，parent 为空时添加为顶级分类
+func AddCategory(cat Category) error {
+	return Update(func(t *Taxonomy) error {
+		if t.Has(cat.Name) {
+			return ErrCategoryExists
+		}
+		t.Categories = append(t.Categories, cat)
+		return nil
+	})
+}
+
+// index 返回分类在列表中的下标，不存在时返回 -1
+func (t *Taxonomy) index(name string) int {
+	for i, cat := range t.Categories {
+		if cat.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// EditCategory 修改分类的关键词、描述、图标、顺序、保护标记等属性；名称和父分类保持不变
+func EditCategory(name string, fn func(cat *Category)) (Category, error) {
+	var result Category
+	err := Update(func(t *Taxonomy) error {
+		i := t.index(name)
+		if i < 0 {
+			return ErrCategoryNotFound
+		}
+		parent := t.Categories[i].Parent
+		fn(&t.Categories[i])
+		t.Categories[i].Name, t.Categories[i].Parent = name, parent
+		result = t.Categories[i]
+		return nil
+	})
+	return result, err
+}
+
+// RenameCategory 重命名分类，子分类的父分类随之更新
+func RenameCategory(oldName, newName string) error {
+	return Update(func(t *Taxonomy) error {
+		i := t.index(oldName)
+		if i < 0 {
+			return ErrCategoryNotFound
+		}
+		if t.Categories[i].Protected {
+			return ErrCategoryProtected
+		}
+		if t.Has(newName) {
+			return ErrCategoryExists
+		}
+		t.Categories[i].Name = newName
+		for j := range t.Categories {
+			if t.Categories[j].Parent == oldName {
+				t.Categories[j].Parent = newName
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteCategory 删除分类，其子分类挂到被删除分类的父分类下
+func DeleteCategory(name string) error {
+	return Update(func(t *Taxonomy) error {
+		i := t.index(name)
+		if i < 0 {
+			return ErrCategoryNotFound
+		}
+		deleted := t.Categories[i]
+		if deleted.Protected {
+			return ErrCategoryProtected
+		}
+		t.Categories = append(t.Categories[:i], t.Categories[i+1:]...)
+		for j := range t.Categories {
+			if t.Categories[j].Parent == name {
+				t.Categories[j].Parent = deleted.Parent
+			}
+		}
+		return nil
+	})
+}
+
+// Reorder 按给定顺序设置分类的显示顺序，未列出的分类保持原顺序值
+func Reorder(names []string) error {
+	return Update(func(t *Taxonomy) error {
+		for n, name := range names {
+			i := t.index(name)
+			if i < 0 {
+				return fmt.Errorf("%w: %s", ErrCategoryNotFound, name)
+			}
+			t.Categories[i].Order = n + 1
+		}
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Price 模型单价，单位为每百万令牌的费用
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// Budget 用量预算，0 表示不限；任一项超出后流水线跳过 AI 分类
+type Budget struct {
+	Daily         float64 `json:"daily,omitempty"`   // 每日费用上限
+	Monthly       float64 `json:"monthly,omitempty"` // 每月费用上限
+	DailyTokens   int     `json:"dailyTokens,omitempty"`
+	MonthlyTokens int     `json:"monthlyTokens,omitempty"`
+}
+
+// Pricing 价格和预算配置
+type Pricing struct {
+	Currency string           `json:"currency"`
+	Models   map[string]Price `json:"models"` // 键为模型名或模型名前缀，"*" 为默认价格
+	Budget   Budget           `json:"budget"`
+}
+
+// BudgetStatus 当前的预算使用情况
+type BudgetStatus struct {
+	Currency string `json:"currency"`
+	Budget   Budget `json:"budget"`
+	Today    Totals `json:"today"`
+	Month    Totals `json:"month"`
+	Exceeded bool   `json:"exceeded"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// DefaultPricing 默认配置：不计价、不限预算
+func DefaultPricing() Pricing {
+	return Pricing{Currency: "CNY", Models: map[string]Price{}}
+}
+
+var currentPricing atomic.Pointer[Pricing]
+
+// CurrentPricing 返回当前的价格和预算配置
+func CurrentPricing() Pricing {
+	if p := currentPricing.Load(); p != nil {
+		return *p
+	}
+	return DefaultPricing()
+}
+
+// SetPricing 替换价格和预算配置
+func SetPricing(p Pricing) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.Currency == "" {
+		p.Currency = "CNY"
+	}
+	if p.Models == nil {
+		p.Models = map[string]Price{}
+	}
+	currentPricing.Store(&p)
+	return nil
+}
+
+// LoadPricing 从配置文件加载价格和预算；文件不存在时使用默认配置
+func LoadPricing(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		log.Printf("未找到价格配置 %s，不计算费用", path)
+		return SetPricing(DefaultPricing())
+	}
+	if err != nil {
+		return fmt.Errorf("读取价格配置失败: %v", err)
+	}
+	var p Pricing
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("解析价格配置失败: %v", err)
+	}
+	if err := SetPricing(p); err != nil {
+		return err
+	}
+	log.Printf("已加载价格配置: %s", path)
+	return nil
+}
+
+// Validate 检查配置中没有负数
+func (p Pricing) Validate() error {
+	for model, price := range p.Models {
+		if price.Prompt < 0 || price.Completion < 0 {
+			return fmt.Errorf("模型 %s 的价格不能为负数", model)
+		}
+	}
+	b := p.Budget
+	if b.Daily < 0 || b.Monthly < 0 || b.DailyTokens < 0 || b.MonthlyTokens < 0 {
+		return fmt.Errorf("预算不能为负数")
+	}
+	return nil
+}
+
+// PriceOf 查找模型的单价：先精确匹配，再取最长的前缀匹配，最后使用 "*"
+func (p Pricing) PriceOf(model string) (Price, bool) {
+	if price, ok := p.Models[model]; ok {
+		return price, true
+	}
+	best, found := "", false
+	for prefix := range p.Models {
+		if prefix != "*" && strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best, found = prefix, true
+		}
+	}
+	if found {
+		return p.Models[best], true
+	}
+	price, ok := p.Models["*"]
+	return price, ok
+}
+
+// Cost 按单价计算一次请求的费用，未配置价格的模型费用为 0
+func (p Pricing) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := p.PriceOf(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1e6
+}
+
+// GetBudgetStatus 返回今日、本月的用量以及是否超出预算
+func GetBudgetStatus() BudgetStatus {
+	p := CurrentPricing()
+	status := BudgetStatus{Currency: p.Currency, Budget: p.Budget}
+	status.Today, status.Month = periodTotals(time.Now())
+	status.Reason = p.Budget.exceeded(status.Today, status.Month)
+	status.Exceeded = status.Reason != ""
+	return status
+}
+
+// exceeded 返回今日、本月用量达到的预算上限，未达到时为空
+func (b Budget) exceeded(today, month Totals) string {
+	switch {
+	case b.Daily > 0 && today.Cost >= b.Daily:
+		return fmt.Sprintf("今日费用 %.4f 已达到上限 %.4f", today.Cost, b.Daily)
+	case b.Monthly > 0 && month.Cost >= b.Monthly:
+		return fmt.Sprintf("本月费用 %.4f 已达到上限 %.4f", month.Cost, b.Monthly)
+	case b.DailyTokens > 0 && today.TotalTokens >= b.DailyTokens:
+		return fmt.Sprintf("今日令牌数 %d 已达到上限 %d", today.TotalTokens, b.DailyTokens)
+	case b.MonthlyTokens > 0 && month.TotalTokens >= b.MonthlyTokens:
+		return fmt.Sprintf("本月令牌数 %d 已达到上限 %d", month.TotalTokens, b.MonthlyTokens)
+	}
+	return ""
+}
@@ -0,0 +1,378 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"file-classifier/internal/models"
+)
+
+// AI 令牌用量和费用记账：每次实际请求 AI 服务（缓存命中不计）记录一条，
+// 按文件、上传批次和用户归属，支持按日、按月汇总，并可设置预算上限。
+// 存储格式为 JSON Lines 追加日志，只追加不修改。
+
+// Account 用量的归属
+type Account struct {
+	Batch string // 上传或扫描批次
+	User  string
+}
+
+// Record 一次 AI 请求的用量
+type Record struct {
+	Time             time.Time `json:"time"`
+	Path             string    `json:"path"`
+	Batch            string    `json:"batch,omitempty"`
+	User             string    `json:"user,omitempty"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model,omitempty"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	TotalTokens      int       `json:"totalTokens"`
+	Cost             float64   `json:"cost"`
+}
+
+// Totals 汇总的用量，定义在 models 中，上传结果可以直接引用
+type Totals = models.UsageTotals
+
+// addTotals 将一条记录计入汇总
+func addTotals(t *Totals, r Record) {
+	t.Calls++
+	t.PromptTokens += r.PromptTokens
+	t.CompletionTokens += r.CompletionTokens
+	t.TotalTokens += r.TotalTokens
+	t.Cost += r.Cost
+}
+
+// subTotals 从汇总中扣除一条记录
+func subTotals(t *Totals, r Record) {
+	t.Calls--
+	t.PromptTokens -= r.PromptTokens
+	t.CompletionTokens -= r.CompletionTokens
+	t.TotalTokens -= r.TotalTokens
+	t.Cost -= r.Cost
+}
+
+// Filter 查询条件，空字段不限制
+type Filter struct {
+	Path  string
+	Batch string
+	User  string
+	Model string
+	From  time.Time // 含
+	To    time.Time // 不含
+}
+
+func (f Filter) match(r Record) bool {
+	return (f.Path == "" || r.Path == f.Path) &&
+		(f.Batch == "" || r.Batch == f.Batch) &&
+		(f.User == "" || r.User == f.User) &&
+		(f.Model == "" || r.Model == f.Model) &&
+		(f.From.IsZero() || !r.Time.Before(f.From)) &&
+		(f.To.IsZero() || r.Time.Before(f.To))
+}
+
+// 汇总的时间粒度和分组维度
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+
+	GroupModel = "model"
+	GroupUser  = "user"
+	GroupBatch = "batch"
+	GroupFile  = "file"
+)
+
+// ErrBudgetExceeded 超出预算时跳过 AI 分类
+var ErrBudgetExceeded = errors.New("AI 用量已超出预算，跳过 AI 分类")
+
+var (
+	records    []Record
+	recordLock sync.RWMutex
+	journal    *os.File
+
+	// 今日、本月的用量（Key 为日期和月份），随 Add 累加，检查预算时无需扫描全部记录
+	dayTotals   Totals
+	monthTotals Totals
+
+	// 进行中的请求预留的用量，请求结束后结算为实际用量
+	reserved Totals
+)
+
+// Init 打开（或创建）用量日志并读取历史记录；未初始化时只在内存中记账
+func Init(path string) error {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	loaded, err := load(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("打开用量日志失败: %v", err)
+	}
+	if journal != nil {
+		journal.Close()
+	}
+	journal = f
+	records = loaded
+	dayTotals, monthTotals = Totals{}, Totals{}
+	for _, r := range loaded {
+		accumulate(r)
+	}
+	return nil
+}
+
+// Close 关闭用量日志
+func Close() error {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+	if journal == nil {
+		return nil
+	}
+	err := journal.Close()
+	journal = nil
+	return err
+}
+
+// Add 记录一次 AI 请求的用量，按当前价格计算费用，返回写入的记录；
+// 写入日志失败时记录仍计入内存中的用量，并返回错误
+func Add(r Record) (Record, error) {
+	r = priced(r)
+	recordLock.Lock()
+	defer recordLock.Unlock()
+	return r, commit(r)
+}
+
+// priced 补全时间和总令牌数，按当前价格计算费用
+func priced(r Record) Record {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.TotalTokens == 0 {
+		r.TotalTokens = r.PromptTokens + r.CompletionTokens
+	}
+	r.Cost = CurrentPricing().Cost(r.Model, r.PromptTokens, r.CompletionTokens)
+	return r
+}
+
+// commit 将记录计入用量并追加到日志，调用方需持有写锁
+func commit(r Record) error {
+	records = append(records, r)
+	accumulate(r)
+	if journal == nil {
+		return nil
+	}
+	data, err := json.Marshal(r)
+	if err == nil {
+		_, err = journal.Write(append(data, '\n'))
+	}
+	if err != nil {
+		return fmt.Errorf("写入用量日志失败: %v", err)
+	}
+	return nil
+}
+
+// Reservation 发出 AI 请求前预留的预算，请求结束后用 Settle 结算实际用量或用 Release 释放
+type Reservation struct {
+	estimate Record
+	active   bool
+}
+
+// Reserve 按估计的用量预留预算：今日、本月的已用量加上其他进行中请求的预留量已达到上限时返回 ErrBudgetExceeded。
+// 检查和预留在同一次加锁中完成，并发分类时不会有一批请求同时通过检查后一起超出上限；未设置预算时不预留
+func Reserve(model string, promptTokens, completionTokens int) (*Reservation, error) {
+	p := CurrentPricing()
+	if p.Budget == (Budget{}) {
+		return &Reservation{}, nil
+	}
+	estimate := Record{
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+		Cost:             p.Cost(model, promptTokens, completionTokens),
+	}
+
+	recordLock.Lock()
+	defer recordLock.Unlock()
+	today, month := periodTotalsLocked(time.Now())
+	for _, t := range []*Totals{&today, &month} {
+		t.TotalTokens += reserved.TotalTokens
+		t.Cost += reserved.Cost
+	}
+	if reason := p.Budget.exceeded(today, month); reason != "" {
+		return nil, fmt.Errorf("%w: %s", ErrBudgetExceeded, reason)
+	}
+	addTotals(&reserved, estimate)
+	return &Reservation{estimate: estimate, active: true}, nil
+}
+
+// Settle 释放预留的用量并记录实际用量，两者在同一次加锁中完成；返回值同 Add
+func (res *Reservation) Settle(r Record) (Record, error) {
+	r = priced(r)
+	recordLock.Lock()
+	defer recordLock.Unlock()
+	res.release()
+	return r, commit(r)
+}
+
+// Release 请求失败时释放预留的用量；重复调用无效
+func (res *Reservation) Release() {
+	recordLock.Lock()
+	defer recordLock.Unlock()
+	res.release()
+}
+
+// release 调用方需持有写锁
+func (res *Reservation) release() {
+	if res.active {
+		subTotals(&reserved, res.estimate)
+		res.active = false
+	}
+}
+
+// Query 返回满足条件的记录，按时间从新到旧排列，limit 为 0 时不限
+func Query(f Filter, limit int) []Record {
+	recordLock.RLock()
+	defer recordLock.RUnlock()
+	result := []Record{}
+	for i := len(records) - 1; i >= 0; i-- {
+		if f.match(records[i]) {
+			result = append(result, records[i])
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Summarize 按时间（day、month）或维度（model、user、batch、file）汇总满足条件的记录，按 Key 排序
+func Summarize(f Filter, by string) ([]Totals, error) {
+	var keyOf func(Record) string
+	switch by {
+	case PeriodDay:
+		keyOf = func(r Record) string { return r.Time.Local().Format("2006-01-02") }
+	case PeriodMonth:
+		keyOf = func(r Record) string { return r.Time.Local().Format("2006-01") }
+	case GroupModel:
+		keyOf = func(r Record) string { return r.Model }
+	case GroupUser:
+		keyOf = func(r Record) string { return r.User }
+	case GroupBatch:
+		keyOf = func(r Record) string { return r.Batch }
+	case GroupFile:
+		keyOf = func(r Record) string { return r.Path }
+	default:
+		return nil, fmt.Errorf("未知的汇总方式: %s", by)
+	}
+
+	recordLock.RLock()
+	groups := make(map[string]*Totals)
+	for _, r := range records {
+		if !f.match(r) {
+			continue
+		}
+		key := keyOf(r)
+		if groups[key] == nil {
+			groups[key] = &Totals{Key: key}
+		}
+		addTotals(groups[key], r)
+	}
+	recordLock.RUnlock()
+
+	result := make([]Totals, 0, len(groups))
+	for _, t := range groups {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// Total 汇总满足条件的全部记录
+func Total(f Filter) Totals {
+	recordLock.RLock()
+	defer recordLock.RUnlock()
+	var t Totals
+	for _, r := range records {
+		if f.match(r) {
+			addTotals(&t, r)
+		}
+	}
+	return t
+}
+
+// accumulate 将记录计入今日、本月的用量：属于更晚的日期或月份时重新开始累计，更早的忽略
+// 调用方需持有写锁
+func accumulate(r Record) {
+	day, month := r.Time.Local().Format("2006-01-02"), r.Time.Local().Format("2006-01")
+	if day > dayTotals.Key {
+		dayTotals = Totals{Key: day}
+	}
+	if day == dayTotals.Key {
+		addTotals(&dayTotals, r)
+	}
+	if month > monthTotals.Key {
+		monthTotals = Totals{Key: month}
+	}
+	if month == monthTotals.Key {
+		addTotals(&monthTotals, r)
+	}
+}
+
+// periodTotals 返回 now 所在日、月的用量
+func periodTotals(now time.Time) (today, month Totals) {
+	recordLock.RLock()
+	defer recordLock.RUnlock()
+	return periodTotalsLocked(now)
+}
+
+// periodTotalsLocked 同 periodTotals，调用方需持有锁
+func periodTotalsLocked(now time.Time) (today, month Totals) {
+	today, month = dayTotals, monthTotals
+	if key := now.Local().Format("2006-01-02"); today.Key != key {
+		today = Totals{Key: key}
+	}
+	if key := now.Local().Format("2006-01"); month.Key != key {
+		month = Totals{Key: key}
+	}
+	return today, month
+}
+
+// load 读取用量日志
+func load(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开用量日志失败: %v", err)
+	}
+	defer f.Close()
+
+	var loaded []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// 进程异常退出可能留下半行，跳过即可
+			continue
+		}
+		loaded = append(loaded, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取用量日志失败: %v", err)
+	}
+	return loaded, nil
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 
 	"file-classifier/internal/config"
 )
@@ -21,3 +24,70 @@ func GetPort() string {
 	}
 	return port
 }
+
+// GetPipelineConfigPath 获取分类流水线配置文件路径
+func GetPipelineConfigPath() string {
+	path := os.Getenv("PIPELINE_CONFIG")
+	if path == "" {
+		path = config.PipelineConfigFile
+	}
+	return path
+}
+
+// GetPricingConfigPath 获取 AI 模型价格和用量预算配置文件路径
+func GetPricingConfigPath() string {
+	path := os.Getenv("PRICING_CONFIG")
+	if path == "" {
+		path = config.PricingConfigFile
+	}
+	return path
+}
+
+// GetTaxonomySourcePath 获取启动时导入的分类体系文档路径
+func GetTaxonomySourcePath() string {
+	path := os.Getenv("TAXONOMY_FILE")
+	if path == "" {
+		path = config.TaxonomySourceFile
+	}
+	return path
+}
+
+// GetEvaluationDir 获取评估接口可用的语料根目录
+func GetEvaluationDir() string {
+	path := os.Getenv("EVALUATION_DIR")
+	if path == "" {
+		path = config.EvaluationDir
+	}
+	return path
+}
+
+// WriteJSONFile 将数据以 JSON 格式写入文件（先写临时文件再原子替换）
+func WriteJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化失败: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("替换文件失败: %v", err)
+	}
+	return nil
+}
+
+// ReadJSONFile 读取 JSON 文件；文件不存在时返回 os.ErrNotExist
+func ReadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", path, err)
+	}
+	return nil
+}
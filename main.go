@@ -3,20 +3,72 @@ package main
 import (
 	"fmt"
 	"log"
-	"math/rand"
-	"time"
 
+	"file-classifier/internal/bayes"
+	"file-classifier/internal/cache"
+	"file-classifier/internal/catalog"
+	"file-classifier/internal/config"
 	"file-classifier/internal/router"
+	"file-classifier/internal/rules"
+	"file-classifier/internal/service"
+	"file-classifier/internal/taxonomy"
+	"file-classifier/internal/usage"
 	"file-classifier/internal/utils"
 )
 
 func main() {
-	// 初始化随机种子
-	rand.Seed(time.Now().UnixNano())
-
 	// 确保上传目录存在
 	utils.EnsureUploadDir()
 
+	// 加载持久化的文件目录
+	if err := catalog.Init(config.CatalogFile); err != nil {
+		log.Fatalf("初始化文件目录失败: %v", err)
+	}
+	defer catalog.Close()
+
+	// 加载文本提取和 AI 结果缓存
+	if err := cache.Init(config.CacheFile); err != nil {
+		log.Fatalf("初始化分类缓存失败: %v", err)
+	}
+	defer cache.Close()
+
+	// 加载 AI 用量记录和价格配置
+	if err := usage.Init(config.UsageFile); err != nil {
+		log.Fatalf("初始化用量记录失败: %v", err)
+	}
+	defer usage.Close()
+	if err := usage.LoadPricing(utils.GetPricingConfigPath()); err != nil {
+		log.Fatalf("加载价格配置失败: %v", err)
+	}
+
+	// 加载本地训练的分类模型
+	if err := bayes.Load(config.BayesModelFile); err != nil {
+		log.Printf("加载本地分类模型失败: %v", err)
+	}
+
+	// 加载分类体系
+	if err := taxonomy.Init(config.TaxonomyFile, config.TaxonomyHistoryDir); err != nil {
+		log.Fatalf("加载分类体系失败: %v", err)
+	}
+
+	// 加载分类规则
+	if err := rules.Init(config.RulesFile); err != nil {
+		log.Fatalf("加载分类规则失败: %v", err)
+	}
+
+	// 导入分类体系文档（版本号高于当前版本时生效）
+	taxonomyFile := utils.GetTaxonomySourcePath()
+	if err := service.LoadTaxonomyFile(taxonomyFile); err != nil {
+		log.Fatalf("加载分类体系文档失败: %v", err)
+	}
+	// 分类体系文档修改后自动重新加载，无需重启
+	go service.WatchTaxonomyFile(taxonomyFile, service.TaxonomyWatchInterval)
+
+	// 加载分类流水线配置
+	if err := service.LoadPipeline(utils.GetPipelineConfigPath()); err != nil {
+		log.Fatalf("加载分类流水线失败: %v", err)
+	}
+
 	// 设置路由
 	r := router.SetupRouter()
 
@@ -1,5 +1,7 @@
 // 全局变量
 let currentStats = {};
+let categoryPolicy = { maxCustomCategories: 3 }; // 分类策略，0 表示不限制自定义分类数量
+let categoryNames = null; // 分类树中的全部分类名，未加载时为 null
 let allFiles = [];
 let currentSort = 'time';
 let currentOrder = 'desc';
@@ -277,6 +279,7 @@ async function loadStats() {
         }
         
         currentStats = await response.json();
+        await loadCategoryPolicy();
         renderCategories();
         
     } catch (error) {
@@ -285,7 +288,66 @@ async function loadStats() {
     }
 }
 
-// 渲染分类卡片 - 固定8个位置的2x4布局
+// 加载分类策略和分类树，失败时沿用上次的结果
+async function loadCategoryPolicy() {
+    try {
+        const response = await fetch('/api/categories');
+        if (!response.ok) {
+            throw new Error(`HTTP ${response.status}`);
+        }
+        const data = await response.json();
+        if (data.policy) {
+            categoryPolicy = data.policy;
+        }
+        if (Array.isArray(data.categories)) {
+            categoryNames = data.categories.map(cat => cat.name);
+        }
+    } catch (error) {
+        console.warn('加载分类策略失败(忽略):', error);
+    }
+}
+
+// 统计自定义分类（含子分类）的数量，与服务端的上限计算一致
+function countCustomCategories(fixedCategories) {
+    let names = categoryNames;
+    if (!names) {
+        names = [];
+        const collect = (stats) => Object.entries(stats || {}).forEach(([name, child]) => {
+            names.push(name);
+            collect(child.children);
+        });
+        collect(currentStats);
+    }
+    return names.filter(name => !fixedCategories.includes(name) && name !== '未分类').length;
+}
+
+// 渲染子分类列表，子分类可继续嵌套
+function renderChildCategories(children) {
+    const list = document.createElement('ul');
+    list.className = 'category-children';
+    Object.keys(children)
+        .sort((a, b) => (children[a].order || 0) - (children[b].order || 0) || a.localeCompare(b))
+        .forEach(name => {
+            const stats = children[name];
+            const item = document.createElement('li');
+            const label = document.createElement('span');
+            label.className = 'category-child';
+            label.textContent = `${name} (${stats.count})`;
+            label.addEventListener('click', (e) => {
+                e.stopPropagation();
+                showFileList(name, stats);
+            });
+            item.appendChild(label);
+            if (stats.children && Object.keys(stats.children).length > 0) {
+                item.appendChild(renderChildCategories(stats.children));
+            }
+            list.appendChild(item);
+        });
+    return list;
+}
+
+// 渲染分类卡片：预定义分类、自定义分类、新增分类入口和未分类
+// 新增分类入口的数量按分类策略中剩余的自定义分类名额显示，不限制时显示一个
 function renderCategories() {
     console.log('开始渲染分类卡片，currentStats:', currentStats);
     categoriesGrid.innerHTML = '';
@@ -304,20 +366,19 @@ function renderCategories() {
     // 固定的分类顺序：前4个预定义分类
     const fixedCategories = ['合同', '简历', '发票', '论文'];
     
-    // 获取新增的分类（排除预定义分类和未分类）
+    // 获取新增的顶级分类（排除预定义分类和未分类），子分类在卡片中显示
     const allCategories = Object.keys(currentStats);
     const newCategories = allCategories.filter(cat => 
         !fixedCategories.includes(cat) && cat !== '未分类'
-    ).slice(0, 3); // 最多3个新增分类
+    ).sort((a, b) => (currentStats[a].order || 0) - (currentStats[b].order || 0) || a.localeCompare(b));
+    
+    const limit = categoryPolicy.maxCustomCategories || 0;
+    const addSlots = limit === 0 ? 1 : Math.max(0, limit - countCustomCategories(fixedCategories));
     
-    // 创建固定的8个位置
     const positions = [
-        // 第一行：4个预定义分类
         ...fixedCategories,
-        // 第二行：3个新增分类位置 + 1个未分类
-        ...Array(3).fill(null).map((_, index) => 
-            newCategories[index] || `add-new-${index}`
-        ),
+        ...newCategories,
+        ...Array(addSlots).fill(null).map((_, index) => `add-new-${index}`),
         '未分类'
     ];
     
@@ -350,9 +411,9 @@ function renderCategories() {
             if (!config) {
                 // 为新分类创建默认配置
                 config = {
-                    icon: 'fas fa-folder',
+                    icon: stats.icon || 'fas fa-folder',
                     color: 'default',
-                    description: '自定义分类'
+                    description: stats.description || '自定义分类'
                 };
             }
             
@@ -364,6 +425,9 @@ function renderCategories() {
                 <div class="category-count">${stats.count}</div>
                 <p class="category-description">${config.description}</p>
             `;
+            if (stats.children && Object.keys(stats.children).length > 0) {
+                card.appendChild(renderChildCategories(stats.children));
+            }
             
             // 添加点击事件
             card.addEventListener('click', () => {
@@ -404,8 +468,11 @@ async function showFileList(categoryName, stats) {
                 
                 const fileIcon = getFileIcon(file.name);
                 const fileSize = formatFileSize(file.size);
-                const badge = file.type === 'ai' ? '<span class="file-badge ai">AI分析</span>' : 
-                             file.type === 'filename' ? '<span class="file-badge">关键词匹配</span>' : '';
+                const explain = classificationExplain(file);
+                const badge = file.type === 'ai' ? `<span class="file-badge ai" title="${explain}">AI分析</span>` : 
+                             file.type === 'content' ? `<span class="file-badge" title="${explain}">内容分析</span>` :
+                             file.type === 'manual' ? `<span class="file-badge" title="${explain}">手动分类</span>` :
+                             file.type === 'filename' ? `<span class="file-badge" title="${explain}">关键词匹配</span>` : '';
                 
                 fileItem.innerHTML = `
                     <i class="${fileIcon} file-icon"></i>
@@ -1042,7 +1109,7 @@ function createFileRow(file) {
     // 分类
     const categoryCell = document.createElement('td');
     const categoryConfig = CATEGORY_CONFIG[file.category] || CATEGORY_CONFIG['未分类'];
-    categoryCell.innerHTML = `<span class="file-table-category ${categoryConfig.color}">${file.category}</span>`;
+    categoryCell.innerHTML = `<span class="file-table-category ${categoryConfig.color}" title="${classificationExplain(file)}">${file.category}</span>`;
     row.appendChild(categoryCell);
     
     // 大小
@@ -1066,6 +1133,18 @@ function createFileRow(file) {
     return row;
 }
 
+// 分类依据说明：置信度 + 原因
+function classificationExplain(file) {
+    const parts = [];
+    if (typeof file.confidence === 'number') {
+        parts.push(`置信度: ${(file.confidence * 100).toFixed(0)}%`);
+    }
+    if (file.reason) {
+        parts.push(file.reason);
+    }
+    return parts.join('\n').replace(/"/g, '&quot;');
+}
+
 // 格式化文件时间
 function formatFileTime(modTime) {
     const date = new Date(modTime);